@@ -0,0 +1,301 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Channel of messages read from an archive, with messages in any order
+type archiveChannel struct {
+	Channel  discordgo.Channel
+	Messages []*discordgo.Message
+}
+
+// Export written by DiscordChatExporter (https://github.com/Tyrrrz/DiscordChatExporter) in JSON format
+type chatExport struct {
+	Guild struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"guild"`
+	Channel struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"channel"`
+	Messages []struct {
+		ID        string `json:"id"`
+		Type      string `json:"type"`
+		Timestamp string `json:"timestamp"`
+		Content   string `json:"content"`
+		Author    struct {
+			ID       string `json:"id"`
+			Name     string `json:"name"`
+			Nickname string `json:"nickname"`
+		} `json:"author"`
+	} `json:"messages"`
+}
+
+// Channel in a Discord data package (messages/c<id>/channel.json). Direct messages have no guild.
+type packageChannel struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Guild *struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"guild"`
+}
+
+// Message in a Discord data package. Older packages have messages.csv with the same columns.
+type packageMessage struct {
+	ID        json.Number `json:"ID"`
+	Timestamp string      `json:"Timestamp"`
+	Contents  string      `json:"Contents"`
+}
+
+// Account that requested a Discord data package (account/user.json). Every message in it is theirs.
+type packageUser struct {
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	GlobalName string `json:"global_name"`
+}
+
+// Read a DiscordChatExporter JSON file, a directory of them, or a Discord data package (as the zip
+// Discord sends or unzipped)
+func readArchiveFile(name string) ([]archiveChannel, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return readArchive(os.DirFS(name))
+	}
+	if strings.EqualFold(filepath.Ext(name), ".zip") {
+		reader, err := zip.OpenReader(name)
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return readArchive(reader)
+	}
+	channel, err := readChatExport(os.DirFS(filepath.Dir(name)), filepath.Base(name))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return []archiveChannel{*channel}, nil
+}
+
+// Read every channel in an archive
+func readArchive(archive fs.FS) ([]archiveChannel, error) {
+	for _, dir := range []string{"messages", "Messages"} {
+		if info, err := fs.Stat(archive, dir); err == nil && info.IsDir() {
+			return readDataPackage(archive, dir)
+		}
+	}
+	var channels []archiveChannel
+	err := fs.WalkDir(archive, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.EqualFold(path.Ext(name), ".json") {
+			return err
+		}
+		channel, err := readChatExport(archive, name)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		channels = append(channels, *channel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("no DiscordChatExporter JSON files or Discord data package found")
+	}
+	return channels, nil
+}
+
+// Read a DiscordChatExporter JSON export of one channel
+func readChatExport(archive fs.FS, name string) (*archiveChannel, error) {
+	data, err := fs.ReadFile(archive, name)
+	if err != nil {
+		return nil, err
+	}
+	var export chatExport
+	err = json.Unmarshal(data, &export)
+	if err != nil {
+		return nil, err
+	}
+	if export.Channel.ID == "" {
+		return nil, fmt.Errorf("not a DiscordChatExporter JSON export")
+	}
+	channel := &archiveChannel{Channel: discordgo.Channel{ID: export.Channel.ID, GuildID: export.Guild.ID, Name: export.Channel.Name}}
+	for _, message := range export.Messages {
+		// Joins, pins, calls and the like are never scores
+		messageType := discordgo.MessageTypeDefault
+		switch message.Type {
+		case "Default":
+		case "Reply":
+			messageType = discordgo.MessageTypeReply
+		default:
+			continue
+		}
+		// Scores are dated from the message ID, so a timestamp in another format is only left out
+		timestamp, _ := time.Parse(time.RFC3339, message.Timestamp)
+		author := &discordgo.User{ID: message.Author.ID, Username: message.Author.Name}
+		// Nickname falls back to the username when the author has no display name
+		if message.Author.Nickname != message.Author.Name {
+			author.GlobalName = message.Author.Nickname
+		}
+		channel.Messages = append(channel.Messages, &discordgo.Message{
+			ID:        message.ID,
+			Type:      messageType,
+			ChannelID: export.Channel.ID,
+			GuildID:   export.Guild.ID,
+			Content:   message.Content,
+			Timestamp: timestamp,
+			Author:    author,
+		})
+	}
+	return channel, nil
+}
+
+// Read the guild channels in a Discord data package. Direct messages are skipped.
+func readDataPackage(archive fs.FS, dir string) ([]archiveChannel, error) {
+	author, err := readPackageUser(archive)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := fs.ReadDir(archive, dir)
+	if err != nil {
+		return nil, err
+	}
+	var channels []archiveChannel
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "c") {
+			continue
+		}
+		channelDir := path.Join(dir, entry.Name())
+		data, err := fs.ReadFile(archive, path.Join(channelDir, "channel.json"))
+		if err != nil {
+			return nil, err
+		}
+		var info packageChannel
+		err = json.Unmarshal(data, &info)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", channelDir, err)
+		}
+		if info.Guild == nil {
+			continue
+		}
+		messages, err := readPackageMessages(archive, channelDir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", channelDir, err)
+		}
+		channel := archiveChannel{Channel: discordgo.Channel{ID: info.ID, GuildID: info.Guild.ID, Name: info.Name}}
+		for _, message := range messages {
+			msg := &discordgo.Message{
+				ID:        message.ID.String(),
+				ChannelID: info.ID,
+				GuildID:   info.Guild.ID,
+				Content:   message.Contents,
+				Author:    author,
+			}
+			// As in chat exports, a timestamp in another format is only left out
+			msg.Timestamp, err = time.Parse("2006-01-02 15:04:05", message.Timestamp)
+			if err != nil {
+				// Older packages have fractional seconds and a time zone
+				msg.Timestamp, _ = time.Parse("2006-01-02 15:04:05.999999-07:00", message.Timestamp)
+			}
+			channel.Messages = append(channel.Messages, msg)
+		}
+		channels = append(channels, channel)
+	}
+	return channels, nil
+}
+
+// Account the data package belongs to
+func readPackageUser(archive fs.FS) (*discordgo.User, error) {
+	for _, name := range []string{"account/user.json", "Account/user.json"} {
+		data, err := fs.ReadFile(archive, name)
+		if err != nil {
+			continue
+		}
+		var user packageUser
+		err = json.Unmarshal(data, &user)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		return &discordgo.User{ID: user.ID, Username: user.Username, GlobalName: user.GlobalName}, nil
+	}
+	return nil, fmt.Errorf("data package has no account/user.json to say whose messages it holds")
+}
+
+// Messages in one channel of a data package, from messages.json or the older messages.csv
+func readPackageMessages(archive fs.FS, dir string) ([]packageMessage, error) {
+	data, err := fs.ReadFile(archive, path.Join(dir, "messages.json"))
+	if err == nil {
+		var messages []packageMessage
+		err = json.Unmarshal(data, &messages)
+		return messages, err
+	}
+	file, err := archive.Open(path.Join(dir, "messages.csv"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var messages []packageMessage
+	for i, row := range rows {
+		if i == 0 || len(row) < 3 {
+			continue
+		}
+		messages = append(messages, packageMessage{ID: json.Number(row[0]), Timestamp: row[1], Contents: row[2]})
+	}
+	return messages, nil
+}
+
+// Import scores from a Discord archive without going to Discord. Messages keep their IDs, so importing
+// again, or scanning the same channel later, doesn't add scores twice.
+func importArchive(name string, out io.Writer) error {
+	channels, err := readArchiveFile(name)
+	if err != nil {
+		return err
+	}
+	for _, channel := range channels {
+		err = store.AddChannel(&channel.Channel)
+		if err != nil {
+			return err
+		}
+		var scores []Score
+		for _, msg := range channel.Messages {
+			parsed, err := ParseScoreFromMessage(msg)
+			if err != nil {
+				err = addUnparsedMessage(msg, err, len(parsed) > 0)
+				if err != nil {
+					return err
+				}
+			}
+			scores = append(scores, parsed...)
+		}
+		for start := 0; start < len(scores); start += importBatchSize {
+			end := min(start+importBatchSize, len(scores))
+			err = store.AddScores(scores[start:end], sourceArchive)
+			if err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(out, "#%s (%s): %d messages, %d scores\n", channel.Channel.Name, channel.Channel.ID, len(channel.Messages), len(scores))
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, name string, content string) {
+	t.Helper()
+	err := os.MkdirAll(filepath.Dir(name), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(name, []byte(content), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportChatExport(t *testing.T) {
+	useMemoryStore(t)
+	export := fmt.Sprintf(`{
+		"guild": {"id": "g1", "name": "Friends"},
+		"channel": {"id": "c1", "type": "GuildTextChat", "name": "games"},
+		"messages": [
+			{"id": "%s", "type": "Default", "timestamp": "05/02/2025 00:00", "content": "Wordle 1,327 4/6",
+				"author": {"id": "u-bob", "name": "bob", "nickname": "Bobby"}},
+			{"id": "%s", "type": "Default", "timestamp": "2025-02-05T00:00:00+00:00", "content": "Wordle 1,327 3/6",
+				"author": {"id": "u-amy", "name": "amy", "nickname": "amy"}},
+			{"id": "%s", "type": "Default", "timestamp": "2025-02-05T00:00:00+00:00", "content": "Wordle 1,327 ?/6 🟩🟩",
+				"author": {"id": "u-amy", "name": "amy", "nickname": "amy"}},
+			{"id": "%s", "type": "ChannelPinnedMessage", "timestamp": "2025-02-05T00:00:00+00:00", "content": "Wordle 1,327 2/6",
+				"author": {"id": "u-amy", "name": "amy", "nickname": "amy"}}
+		]
+	}`, testMessageID(0), testMessageID(1), testMessageID(2), testMessageID(3))
+	name := filepath.Join(t.TempDir(), "Friends - games [c1].json")
+	writeTestFile(t, name, export)
+	for range 2 {
+		err := importArchive(name, &strings.Builder{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats, err := store.GetStats("Wordle", "g1", testFrom, testTo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 {
+		t.Errorf("expected bob and amy once each, even with a timestamp in another format, got %+v", stats)
+	}
+	player, err := store.GetPlayer("u-bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if player.Name != "Bobby" {
+		t.Errorf("expected bob's nickname, got %+v", player)
+	}
+	unparsed, err := store.GetUnparsedMessages("pending")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unparsed) != 1 || unparsed[0].MessageID != testMessageID(2) {
+		t.Errorf("expected the broken share for review, got %+v", unparsed)
+	}
+	log, err := store.GetAuditLog(sourceArchive, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log) != 2 {
+		t.Errorf("expected 2 scores inserted from the archive, got %+v", log)
+	}
+}
+
+// Files of a Discord data package for bob, with a guild channel and a direct message. Older packages
+// have messages.csv instead of messages.json.
+func testDataPackage(csv bool) map[string]string {
+	files := map[string]string{
+		"account/user.json":         `{"id": "u-bob", "username": "bob", "global_name": "Bobby"}`,
+		"messages/index.json":       `{"c1": "games in Friends", "c2": "Direct Message with amy"}`,
+		"messages/c1/channel.json":  `{"id": "c1", "type": 0, "name": "games", "guild": {"id": "g1", "name": "Friends"}}`,
+		"messages/c2/channel.json":  `{"id": "c2", "type": 1, "recipients": ["u-bob", "u-amy"]}`,
+		"messages/c2/messages.json": `[]`,
+	}
+	if csv {
+		files["messages/c1/messages.csv"] = fmt.Sprintf("ID,Timestamp,Contents,Attachments\n%s,2025-02-05 00:00:00.000000+00:00,\"Wordle 1,327 4/6\",\n", testMessageID(0))
+	} else {
+		files["messages/c1/messages.json"] = fmt.Sprintf(`[{"ID": %s, "Timestamp": "2025-02-05 00:00:00", "Contents": "Wordle 1,327 4/6", "Attachments": ""}]`, testMessageID(0))
+	}
+	return files
+}
+
+func TestImportDataPackage(t *testing.T) {
+	t.Run("directory", func(t *testing.T) {
+		useMemoryStore(t)
+		dir := t.TempDir()
+		for name, content := range testDataPackage(false) {
+			writeTestFile(t, filepath.Join(dir, name), content)
+		}
+		var out strings.Builder
+		err := importArchive(dir, &out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkDataPackageImport(t, out.String())
+	})
+	t.Run("zip", func(t *testing.T) {
+		useMemoryStore(t)
+		name := filepath.Join(t.TempDir(), "package.zip")
+		file, err := os.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := zip.NewWriter(file)
+		for name, content := range testDataPackage(true) {
+			f, err := w.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.Write([]byte(content))
+		}
+		w.Close()
+		file.Close()
+		var out strings.Builder
+		err = importArchive(name, &out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkDataPackageImport(t, out.String())
+	})
+}
+
+func checkDataPackageImport(t *testing.T, out string) {
+	t.Helper()
+	scores, err := store.GetScoresByUser("Wordle", "u-bob", testFrom, testTo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scores) != 1 || scores[0].Score != "4" || scores[0].MessageID != testMessageID(0) {
+		t.Errorf("expected bob's score with its message ID, got %+v", scores)
+	}
+	channel, err := store.GetChannel("c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if channel.GuildID != "g1" || channel.Name != "games" {
+		t.Errorf("expected channel info from the package, got %+v", channel)
+	}
+	if strings.Contains(out, "c2") {
+		t.Errorf("expected direct messages skipped:\n%s", out)
+	}
+}
+
+func TestImportArchiveErrors(t *testing.T) {
+	useMemoryStore(t)
+	dir := t.TempDir()
+	if importArchive(dir, &strings.Builder{}) == nil {
+		t.Error("expected an empty directory to be refused")
+	}
+	name := filepath.Join(dir, "other.json")
+	writeTestFile(t, name, `{"hello": "world"}`)
+	if importArchive(name, &strings.Builder{}) == nil {
+		t.Error("expected JSON that isn't an export to be refused")
+	}
+	writeTestFile(t, filepath.Join(dir, "package", "messages", "c1", "channel.json"), `{"id": "c1"}`)
+	if importArchive(filepath.Join(dir, "package"), &strings.Builder{}) == nil {
+		t.Error("expected a data package without an account to be refused")
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Where a score change came from
+const (
+	sourceMonitor  = "monitor"  // Live messages and the monitor's periodic scans
+	sourceRescan   = "rescan"   // rescan command
+	sourceUpdate   = "update"   // update command
+	sourceBackfill = "backfill" // backfill command, filling in player IDs
+	sourceReparse  = "reparse"  // reparse command
+	sourceManual   = "manual"   // Entered by hand on the review page
+	sourceImport   = "import"   // import command
+	sourceArchive  = "archive"  // archive command, from a DiscordChatExporter export or data package
+)
+
+// Sortable to the microsecond, unlike RFC3339Nano which drops trailing zeros
+const auditTimeFormat = "2006-01-02 15:04:05.000000"
+
+// Change to a score
+type ScoreAudit struct {
+	ScoreID string
+	Action  string // insert, replace or delete
+	Old     *Score // nil when inserted
+	New     *Score // nil when deleted
+	Source  string
+	Changed string // UTC
+}
+
+func (audit ScoreAudit) String() string {
+	describe := func(score *Score) string {
+		if score == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%s %s: %s (%s)", score.Game, score.GameNumber, score.Score, score.Win)
+	}
+	return fmt.Sprintf("%s  %-8s %-7s %s  %s -> %s", audit.Changed, audit.Source, audit.Action, audit.ScoreID, describe(audit.Old), describe(audit.New))
+}
+
+// Score as kept in the audit log. Guesses come from the content, and names from the players table.
+func auditSnapshot(score *Score) (sql.NullString, error) {
+	if score == nil {
+		return sql.NullString{}, nil
+	}
+	snapshot := *score
+	snapshot.Guesses = nil
+	snapshot.Name = ""
+	if len(snapshot.Details) == 0 {
+		snapshot.Details = nil
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// Record a change to a score. Saving a score again without changes isn't recorded.
+func addAudit(tx dbtx, scoreID string, old *Score, new *Score, source string) error {
+	oldSnapshot, err := auditSnapshot(old)
+	if err != nil {
+		return err
+	}
+	newSnapshot, err := auditSnapshot(new)
+	if err != nil {
+		return err
+	}
+	action := "replace"
+	switch {
+	case old == nil:
+		action = "insert"
+	case new == nil:
+		action = "delete"
+	case oldSnapshot == newSnapshot:
+		return nil
+	}
+	_, err = tx.Exec(`
+		INSERT INTO score_audit (score_id, action, old_score, new_score, source, changed)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, scoreID, action, oldSnapshot, newSnapshot, source, time.Now().UTC().Format(auditTimeFormat))
+	return err
+}
+
+// Record saving a score over what it replaced. Reposts of the same result replace the earlier message's
+// score, so that is recorded as a delete.
+func auditReplacedScores(tx dbtx, replaced []Score, score *Score, source string) error {
+	var old *Score
+	for i := range replaced {
+		if replaced[i].ID == score.ID {
+			old = &replaced[i]
+			continue
+		}
+		err := addAudit(tx, replaced[i].ID, &replaced[i], nil, source)
+		if err != nil {
+			return err
+		}
+	}
+	return addAudit(tx, score.ID, old, score, source)
+}
+
+// Changes to a score, oldest first
+func (s *sqlStore) GetScoreHistory(scoreID string) ([]ScoreAudit, error) {
+	return s.readAudit("WHERE score_id = ? ORDER BY changed", scoreID)
+}
+
+// Latest changes, newest first. Filter by source with a non-empty source.
+func (s *sqlStore) GetAuditLog(source string, limit int) ([]ScoreAudit, error) {
+	return s.readAudit("WHERE ? = '' OR source = ? ORDER BY changed DESC LIMIT ?", source, source, limit)
+}
+
+func (s *sqlStore) readAudit(filter string, args ...any) ([]ScoreAudit, error) {
+	rows, err := s.db.Query(`
+		SELECT score_id, action, COALESCE(old_score, ''), COALESCE(new_score, ''), source, changed
+		FROM score_audit
+		`+filter, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %v", err)
+	}
+	defer rows.Close()
+	var audits []ScoreAudit
+	for rows.Next() {
+		var audit ScoreAudit
+		var old, new string
+		err := rows.Scan(&audit.ScoreID, &audit.Action, &old, &new, &audit.Source, &audit.Changed)
+		if err != nil {
+			return nil, err
+		}
+		if old != "" {
+			audit.Old = &Score{}
+			err = json.Unmarshal([]byte(old), audit.Old)
+			if err != nil {
+				return nil, fmt.Errorf("invalid audit entry for %s: %v", audit.ScoreID, err)
+			}
+		}
+		if new != "" {
+			audit.New = &Score{}
+			err = json.Unmarshal([]byte(new), audit.New)
+			if err != nil {
+				return nil, fmt.Errorf("invalid audit entry for %s: %v", audit.ScoreID, err)
+			}
+		}
+		audits = append(audits, audit)
+	}
+	return audits, rows.Err()
+}
+
+// Print the audit log for a score, or the latest changes
+func printAuditLog(scoreID string, source string, limit int, out io.Writer) error {
+	var audits []ScoreAudit
+	var err error
+	if scoreID != "" {
+		audits, err = store.GetScoreHistory(scoreID)
+	} else {
+		audits, err = store.GetAuditLog(source, limit)
+	}
+	if err != nil {
+		return err
+	}
+	if len(audits) == 0 {
+		fmt.Fprintln(out, "No changes recorded.")
+		return nil
+	}
+	for _, audit := range audits {
+		fmt.Fprintln(out, audit)
+	}
+	return nil
+}
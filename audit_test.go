@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestScoreAudit(t *testing.T) {
+	testStores(t, func(t *testing.T) {
+		save := func(id int, content string, source string) {
+			t.Helper()
+			msg := &discordgo.Message{ID: testMessageID(id), ChannelID: "c1", Author: &discordgo.User{ID: "1", Username: "bob"}, Content: content}
+			scores, err := ParseScoreFromMessage(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = store.AddScores(scores, source)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		save(0, "Wordle 1,327 4/6", sourceMonitor)
+		// Rescanning without changes isn't recorded
+		save(0, "Wordle 1,327 4/6", sourceRescan)
+		// An edited message
+		save(0, "Wordle 1,327 3/6", sourceRescan)
+		history, err := store.GetScoreHistory(scoreID(testMessageID(0), "Wordle"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) != 2 || history[0].Action != "insert" || history[0].Source != sourceMonitor {
+			t.Fatalf("unexpected history %+v", history)
+		}
+		if history[1].Action != "replace" || history[1].Source != sourceRescan || history[1].Old.Score != "4" || history[1].New.Score != "3" {
+			t.Errorf("expected 4 replaced by 3 on rescan, got %s", history[1])
+		}
+		// Reposting the same result replaces the earlier message's score
+		save(1, "Wordle 1,327 3/6", sourceManual)
+		history, err = store.GetScoreHistory(scoreID(testMessageID(0), "Wordle"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) != 3 || history[2].Action != "delete" || history[2].New != nil {
+			t.Errorf("expected the earlier score to be deleted, got %+v", history)
+		}
+		log, err := store.GetAuditLog(sourceManual, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(log) != 2 {
+			t.Errorf("expected a delete and an insert from manual, got %+v", log)
+		}
+	})
+}
+
+func TestScoreHandler(t *testing.T) {
+	useMemoryStore(t)
+	addTestScores(t, map[string][]string{"bob": {"Wordle 1,327 4/6"}})
+	w := httptest.NewRecorder()
+	scoreHandler(w, httptest.NewRequest("GET", "/score?id="+scoreID(testMessageID(0), "Wordle"), nil))
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	if !strings.Contains(w.Body.String(), "insert") {
+		t.Errorf("expected the insert in the history:\n%s", w.Body)
+	}
+}
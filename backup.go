@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Pages copied per backup step. The source is only locked while a step runs, so the bot and monitor can
+// keep saving scores during a backup.
+const backupStepPages = 1024
+
+// Copy one SQLite database over another with SQLite's online backup API
+func sqliteBackup(dest *sql.DB, src *sql.DB) error {
+	ctx := context.Background()
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+	return destConn.Raw(func(destDriver any) error {
+		return srcConn.Raw(func(srcDriver any) error {
+			destSQLite, ok := destDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("backups need a SQLite database")
+			}
+			srcSQLite, ok := srcDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("backups need a SQLite database")
+			}
+			backup, err := destSQLite.Backup("main", srcSQLite, "main")
+			if err != nil {
+				return err
+			}
+			for {
+				done, err := backup.Step(backupStepPages)
+				if err != nil {
+					backup.Finish()
+					return err
+				}
+				if done {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			return backup.Finish()
+		})
+	})
+}
+
+// Write a copy of the database to a new SQLite file. Safe while the bot and monitor are running.
+func (s *sqlStore) Backup(path string) error {
+	if s.db.postgres {
+		return fmt.Errorf("back up PostgreSQL databases with pg_dump")
+	}
+	_, err := os.Stat(path)
+	if err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	// Written under another name first so a failed backup doesn't look like a good one
+	partial := path + ".partial"
+	os.Remove(partial)
+	dest, err := sql.Open("sqlite3", partial)
+	if err != nil {
+		return err
+	}
+	err = sqliteBackup(dest, s.db.DB)
+	dest.Close()
+	if err != nil {
+		os.Remove(partial)
+		return fmt.Errorf("failed to back up to %s: %v", path, err)
+	}
+	return os.Rename(partial, path)
+}
+
+// Replace a SQLite database with a backup, then bring its schema up to date. Backups from a newer
+// build are refused.
+func restoreDatabase(dsn string, from string, confirm bool, in io.Reader, out io.Writer) error {
+	if isPostgresDSN(dsn) {
+		return fmt.Errorf("restore PostgreSQL databases with pg_restore")
+	}
+	_, err := os.Stat(from)
+	if err != nil {
+		return err
+	}
+	src, err := sql.Open("sqlite3", "file:"+from+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	var version int
+	err = src.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version)
+	if err != nil {
+		return fmt.Errorf("%s is not a backup of this database: %v", from, err)
+	}
+	if version > latestSchemaVersion() {
+		return fmt.Errorf("backup schema version %d is newer than this build supports (%d), upgrade %s", version, latestSchemaVersion(), appExecName())
+	}
+	if confirm {
+		fmt.Fprintf(out, "Replace %s with %s? [y/N] ", dsn, from)
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+			fmt.Fprintln(out, "Nothing restored.")
+			return nil
+		}
+	}
+	dest, err := openDatabase(dsn)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+	err = sqliteBackup(dest.DB, src)
+	if err != nil {
+		return fmt.Errorf("failed to restore %s: %v", from, err)
+	}
+	err = migrateDatabase(dest)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Restored %s from %s\n", dsn, from)
+	return nil
+}
+
+// Snapshot file names sort by the time they were taken
+const snapshotPrefix = "scores-"
+const snapshotTimeFormat = "20060102-150405"
+
+// Back up the store into a directory, keeping the newest snapshots
+func takeSnapshot(dir string, keep int) (string, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, snapshotPrefix+time.Now().UTC().Format(snapshotTimeFormat)+".db")
+	err = store.Backup(path)
+	if err != nil {
+		return "", err
+	}
+	return path, rotateSnapshots(dir, keep)
+}
+
+// Delete all but the newest snapshots
+func rotateSnapshots(dir string, keep int) error {
+	snapshots, err := filepath.Glob(filepath.Join(dir, snapshotPrefix+"*.db"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(snapshots)
+	for len(snapshots) > keep {
+		err = os.Remove(snapshots[0])
+		if err != nil {
+			return err
+		}
+		snapshots = snapshots[1:]
+	}
+	return nil
+}
+
+// Take a snapshot now and then every interval. Failures are logged so the monitor keeps running.
+func startSnapshots(dir string, interval time.Duration, keep int) {
+	for {
+		path, err := takeSnapshot(dir, keep)
+		if err != nil {
+			logPrintln("Snapshot failed: %v", err)
+		} else {
+			logPrintln("Saved snapshot %s", path)
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	useMemoryStore(t)
+	addTestScores(t, map[string][]string{"bob": {"Wordle 1,327 4/6"}, "amy": {"Wordle 1,327 3/6"}})
+	dir := t.TempDir()
+	backup := filepath.Join(dir, "backup.db")
+	err := store.Backup(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.Backup(backup) == nil {
+		t.Error("expected an existing backup to be kept")
+	}
+	// Declining leaves the database alone
+	restored := filepath.Join(dir, "scores.db")
+	var out strings.Builder
+	err = restoreDatabase(restored, backup, true, strings.NewReader("n\n"), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(restored); err == nil {
+		t.Errorf("expected nothing restored:\n%s", out.String())
+	}
+	err = restoreDatabase(restored, backup, true, strings.NewReader("y\n"), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	useStore(t, openTestStore(t, restored))
+	stats, err := store.GetStats("Wordle", "g1", testFrom, testTo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 {
+		t.Errorf("expected bob and amy in the restored database, got %+v", stats)
+	}
+}
+
+func TestRestoreRefusesNewerBackup(t *testing.T) {
+	memory := useMemoryStore(t)
+	_, err := memory.db.Exec("INSERT INTO schema_version (version, name, applied) VALUES (?, 'future', '')", latestSchemaVersion()+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	backup := filepath.Join(dir, "backup.db")
+	err = store.Backup(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = restoreDatabase(filepath.Join(dir, "scores.db"), backup, false, nil, &strings.Builder{})
+	if err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("expected a newer backup to be refused, got %v", err)
+	}
+}
+
+func TestRotateSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{"scores-20250201-000000.db", "scores-20250203-000000.db", "scores-20250202-000000.db", "notes.txt"}
+	for _, name := range names {
+		err := os.WriteFile(filepath.Join(dir, name), nil, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := rotateSnapshots(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kept []string
+	for _, entry := range entries {
+		kept = append(kept, entry.Name())
+	}
+	if strings.Join(kept, " ") != "notes.txt scores-20250202-000000.db scores-20250203-000000.db" {
+		t.Errorf("expected the two newest snapshots kept, got %v", kept)
+	}
+}
+
+func TestTakeSnapshot(t *testing.T) {
+	useMemoryStore(t)
+	addTestScores(t, map[string][]string{"bob": {"Wordle 1,327 4/6"}})
+	dir := filepath.Join(t.TempDir(), "snapshots")
+	path, err := takeSnapshot(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := openTestStore(t, path)
+	defer snapshot.Close()
+	scores, err := snapshot.GetScoresByMessage(testMessageID(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scores) != 1 {
+		t.Errorf("expected bob's score in the snapshot, got %+v", scores)
+	}
+}
+
+func openTestStore(t *testing.T, path string) *sqlStore {
+	t.Helper()
+	opened, err := newSQLStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return opened
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Tables copied by copy-db. schema_version is left to each database's own migrations.
+var copyTables = []string{"guild_settings", "channels", "puzzles", "players", "player_names", "player_aliases", "scores", "guesses", "score_details", "score_audit", "unparsed"}
+
+// Copy every row from one database to another, such as SQLite to PostgreSQL. Rows already in the
+// target are kept, so a copy can be run again to pick up new scores.
+func copyDatabase(from *database, to *database, out io.Writer) error {
+	tx, err := to.Begin()
+	if err != nil {
+		return err
+	}
+	for _, table := range copyTables {
+		count, err := copyTable(from, tx, table)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to copy %s: %v", table, err)
+		}
+		fmt.Fprintf(out, "%-15s %d rows\n", table, count)
+	}
+	return tx.Commit()
+}
+
+// Copy one table, returning the number of rows added
+func copyTable(from *database, to *transaction, table string) (int64, error) {
+	rows, err := from.Query(fmt.Sprintf("SELECT * FROM %s", table))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	stmt, err := to.Prepare(to.db.rebind(fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s) ON CONFLICT DO NOTHING",
+		table, strings.Join(columns, ", "), placeholders,
+	)))
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+	var count int64
+	values := make([]any, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		err := rows.Scan(pointers...)
+		if err != nil {
+			return count, err
+		}
+		// SQLite hands back TEXT as bytes, which PostgreSQL would store as bytea
+		for i, value := range values {
+			if b, ok := value.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		result, err := stmt.Exec(values...)
+		if err != nil {
+			return count, err
+		}
+		added, _ := result.RowsAffected()
+		count += added
+	}
+	return count, rows.Err()
+}
@@ -3,115 +3,328 @@ package main
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
+	"github.com/bwmarrin/discordgo"
+	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// Open database connection
-func initDatabase() (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", "./scores.db")
+// Store backed by SQLite or PostgreSQL. Queries are written in SQL both understand, with ? placeholders.
+type sqlStore struct {
+	db *database
+}
+
+// Database connection that rewrites placeholders for the driver in use
+type database struct {
+	*sql.DB
+	postgres bool
+}
+
+// Check if a DSN is for PostgreSQL rather than a SQLite file
+func isPostgresDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://")
+}
+
+// Open a database without touching the schema. Used by migrate to inspect a database.
+func openDatabase(dsn string) (*database, error) {
+	if isPostgresDSN(dsn) {
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			return nil, err
+		}
+		return &database{db, true}, nil
+	}
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, err
 	}
-	// Scores
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS scores (
-			id TEXT UNIQUE,
-			channel_id TEXT,
-			username TEXT,
-			game TEXT,
-			game_number TEXT,
-			score TEXT,
-			win TEXT,
-			hardmode TEXT,
-			UNIQUE (username, game, game_number, score)
-		)
-	`)
+	// Each connection to ":memory:" is a separate database, so keep to one
+	if strings.Contains(dsn, ":memory:") || strings.Contains(dsn, "mode=memory") {
+		db.SetMaxOpenConns(1)
+	}
+	return &database{db, false}, nil
+}
+
+// Replace ? placeholders with $1, $2, ... for PostgreSQL. Quoted text is left alone.
+func (db *database) rebind(query string) string {
+	if !db.postgres {
+		return query
+	}
+	var builder strings.Builder
+	n := 0
+	quoted := false
+	for _, c := range query {
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case c == '?' && !quoted:
+			n++
+			fmt.Fprintf(&builder, "$%d", n)
+			continue
+		}
+		builder.WriteRune(c)
+	}
+	return builder.String()
+}
+
+func (db *database) Exec(query string, args ...any) (sql.Result, error) {
+	return db.DB.Exec(db.rebind(query), args...)
+}
+
+func (db *database) Query(query string, args ...any) (*sql.Rows, error) {
+	return db.DB.Query(db.rebind(query), args...)
+}
+
+func (db *database) QueryRow(query string, args ...any) *sql.Row {
+	return db.DB.QueryRow(db.rebind(query), args...)
+}
+
+func (db *database) Prepare(query string) (*sql.Stmt, error) {
+	return db.DB.Prepare(db.rebind(query))
+}
+
+func (db *database) Begin() (*transaction, error) {
+	tx, err := db.DB.Begin()
 	if err != nil {
 		return nil, err
 	}
-	// Channels
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS channels (
-			channel_id TEXT UNIQUE,
-			guild_id TEXT,
-			name TEXT
-		)
-	`)
+	return &transaction{tx, db}, nil
+}
+
+// Transaction that rewrites placeholders like its database
+type transaction struct {
+	*sql.Tx
+	db *database
+}
+
+func (tx *transaction) Exec(query string, args ...any) (sql.Result, error) {
+	return tx.Tx.Exec(tx.db.rebind(query), args...)
+}
+
+func (tx *transaction) Query(query string, args ...any) (*sql.Rows, error) {
+	return tx.Tx.Query(tx.db.rebind(query), args...)
+}
+
+func (tx *transaction) QueryRow(query string, args ...any) *sql.Row {
+	return tx.Tx.QueryRow(tx.db.rebind(query), args...)
+}
+
+// Open a store and bring the schema up to date. Refuses databases written by a newer build.
+//
+// DSNs starting with postgres:// or postgresql:// use PostgreSQL, anything else is a SQLite file.
+func newSQLStore(dsn string) (*sqlStore, error) {
+	db, err := openDatabase(dsn)
 	if err != nil {
 		return nil, err
 	}
-	// Puzzles
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS puzzles (
-			game TEXT,
-			game_number TEXT,
-			date TEXT,
-			solution TEXT,
-			UNIQUE (game, game_number)
-		)
-	`)
+	err = migrateDatabase(db)
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
-	return db, nil
+	return &sqlStore{db}, nil
 }
 
-var _db *sql.DB
+// Empty store that lives as long as it is open. Used by tests.
+func newMemoryStore() (*sqlStore, error) {
+	return newSQLStore(":memory:")
+}
 
-// Get a connection to database. Reuses a shared connection if one is available.
-func getDatabase() (*sql.DB, error) {
-	if _db != nil {
-		return _db, nil
-	}
-	db, err := initDatabase()
-	_db = db
-	return db, err
+func (s *sqlStore) Close() error {
+	return s.db.Close()
 }
 
-// Add scores to database
-func addScores(scores []Score) error {
-	db, err := getDatabase()
+// Add scores to database. Changes are recorded in the audit log under source.
+//
+// A player posting the same puzzle again is counted once, following the guild's duplicate policy. Reposts
+// with a different result that don't count go to the review queue. Scores entered by hand always count.
+func (s *sqlStore) AddScores(scores []Score, source string) error {
+	db := s.db
+	clear_score_stmt, err := db.Prepare("DELETE FROM scores WHERE id = ?")
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to prepare score statement: %v", err)
 	}
+	defer clear_score_stmt.Close()
 	score_stmt, err := db.Prepare(`
-		INSERT OR REPLACE INTO scores (id, channel_id, username, game, game_number, score, win, hardmode)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO scores (id, message_id, channel_id, player_id, username, game, game_number, score, win, hardmode, content, game_number_text, score_text)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare score statement: %v", err)
 	}
 	defer score_stmt.Close()
+	// Dates from a game's numbering always apply. Dates guessed from the post keep the earliest.
 	puzzle_stmt, err := db.Prepare(`
-		INSERT OR IGNORE INTO puzzles (game, game_number, date)
+		INSERT INTO puzzles (game, game_number, date)
 		VALUES (?, ?, ?)
+		ON CONFLICT (game, game_number) DO UPDATE SET date = excluded.date
+		WHERE excluded.date < puzzles.date
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare puzzle statement: %v", err)
 	}
-	defer score_stmt.Close()
+	defer puzzle_stmt.Close()
+	canonical_puzzle_stmt, err := db.Prepare(`
+		INSERT INTO puzzles (game, game_number, date)
+		VALUES (?, ?, ?)
+		ON CONFLICT (game, game_number) DO UPDATE SET date = excluded.date
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare puzzle statement: %v", err)
+	}
+	defer canonical_puzzle_stmt.Close()
+	clear_guesses_stmt, err := db.Prepare("DELETE FROM guesses WHERE score_id = ?")
+	if err != nil {
+		return fmt.Errorf("failed to prepare guess statement: %v", err)
+	}
+	defer clear_guesses_stmt.Close()
+	guess_stmt, err := db.Prepare(`
+		INSERT INTO guesses (score_id, board, row, tiles)
+		VALUES (?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare guess statement: %v", err)
+	}
+	defer guess_stmt.Close()
+	clear_details_stmt, err := db.Prepare("DELETE FROM score_details WHERE score_id = ?")
+	if err != nil {
+		return fmt.Errorf("failed to prepare detail statement: %v", err)
+	}
+	defer clear_details_stmt.Close()
+	detail_stmt, err := db.Prepare(`
+		INSERT INTO score_details (score_id, name, value)
+		VALUES (?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare detail statement: %v", err)
+	}
+	defer detail_stmt.Close()
+	resolve_stmt, err := db.Prepare(`
+		UPDATE unparsed SET status = 'resolved'
+		WHERE message_id = ? AND status = 'pending' AND COALESCE(reason, '') NOT IN ('repost', 'partial')
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare review statement: %v", err)
+	}
+	defer resolve_stmt.Close()
 
 	tx, err := db.Begin()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %v", err)
 	}
+	// Remove a score along with its guesses and details
+	deleteScore := func(scoreID string) error {
+		for _, stmt := range []*sql.Stmt{clear_score_stmt, clear_guesses_stmt, clear_details_stmt} {
+			_, err := tx.Stmt(stmt).Exec(scoreID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	policies := map[string]string{}
 	for _, score := range scores {
-		_, err := tx.Stmt(score_stmt).Exec(score.ID, score.ChannelID, score.Username, score.Game, score.GameNumber, score.Score, score.Win, score.Hardmode)
+		policy, ok := policies[score.ChannelID]
+		if !ok {
+			policy, err = channelDuplicatePolicy(tx, score.ChannelID)
+			if err != nil {
+				tx.Rollback()
+				return fmt.Errorf("failed to get duplicate policy %s: %v", score.ChannelID, err)
+			}
+			policies[score.ChannelID] = policy
+		}
+		replaced, err := readScores(tx, "id = ?", score.ID)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to read score %s: %v", score.ID, err)
+		}
+		// A score saved again is deleted and added back
+		for _, old := range replaced {
+			err = deleteScore(old.ID)
+			if err != nil {
+				tx.Rollback()
+				return fmt.Errorf("failed to replace score %s: %v", old.ID, err)
+			}
+		}
+		counts, removed, err := settleDuplicates(tx, &score, policy, source, deleteScore)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if !counts {
+			for _, old := range append(replaced, removed...) {
+				err = addAudit(tx, old.ID, &old, nil, source)
+				if err != nil {
+					tx.Rollback()
+					return fmt.Errorf("failed to audit score %s: %v", old.ID, err)
+				}
+			}
+			continue
+		}
+		replaced = append(replaced, removed...)
+		_, err = tx.Stmt(score_stmt).Exec(score.ID, score.MessageID, score.ChannelID, nullString(score.PlayerID), score.Username, score.Game, score.Number, score.Value, score.Win, score.Hardmode, score.Content, score.GameNumber, score.Score)
 		if err != nil {
 			tx.Rollback()
 			return fmt.Errorf("failed to add score %s: %v", score.ID, err)
 		}
-		date, err := dateFromDiscordSnowflake(score.ID)
+		// Scores without a name, such as reparsed or hand-entered ones, leave the player's names alone
+		if score.PlayerID != "" && score.Name != "" {
+			err = addPlayer(tx, score.PlayerID, score.Username, score.DisplayName(), score.MessageID)
+			if err != nil {
+				tx.Rollback()
+				return fmt.Errorf("failed to add player %s: %v", score.PlayerID, err)
+			}
+		}
+		_, err = tx.Stmt(clear_guesses_stmt).Exec(score.ID)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to clear guesses %s: %v", score.ID, err)
+		}
+		for _, guess := range score.Guesses {
+			_, err = tx.Stmt(guess_stmt).Exec(score.ID, guess.Board, guess.Row, guess.Tiles)
+			if err != nil {
+				tx.Rollback()
+				return fmt.Errorf("failed to add guess %s: %v", score.ID, err)
+			}
+		}
+		_, err = tx.Stmt(clear_details_stmt).Exec(score.ID)
 		if err != nil {
 			tx.Rollback()
-			return fmt.Errorf("failed to get snowflake %s: %v", score.ID, err)
+			return fmt.Errorf("failed to clear details %s: %v", score.ID, err)
+		}
+		for name, value := range score.Details {
+			_, err = tx.Stmt(detail_stmt).Exec(score.ID, name, value)
+			if err != nil {
+				tx.Rollback()
+				return fmt.Errorf("failed to add detail %s: %v", score.ID, err)
+			}
 		}
-		_, err = tx.Stmt(puzzle_stmt).Exec(score.Game, score.GameNumber, date)
+		date, canonical, err := puzzleDate(score.Game, score.Number, score.MessageID)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to get puzzle date %s: %v", score.ID, err)
+		}
+		stmt := puzzle_stmt
+		if canonical {
+			stmt = canonical_puzzle_stmt
+		}
+		_, err = tx.Stmt(stmt).Exec(score.Game, score.Number, date)
 		if err != nil {
 			tx.Rollback()
 			return fmt.Errorf("failed to add puzzle %s: %v", score.ID, err)
 		}
+		err = auditReplacedScores(tx, replaced, &score, source)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to audit score %s: %v", score.ID, err)
+		}
+		// A message that parses now (parser fix, edit or hand entry) no longer needs review
+		_, err = tx.Stmt(resolve_stmt).Exec(score.MessageID)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to resolve review %s: %v", score.ID, err)
+		}
 	}
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %v", err)
@@ -120,15 +333,72 @@ func addScores(scores []Score) error {
 	return nil
 }
 
-// Grab oldest and newest id. Used to download incrementally.
-func getScoreIDRange() (string, string, error) {
-	db, err := getDatabase()
+// Delete scores along with their guesses and details. Deletions are recorded in the audit log under source.
+func (s *sqlStore) DeleteScores(scoreIDs []string, source string) error {
+	tx, err := s.db.Begin()
 	if err != nil {
-		return "", "", err
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	for _, scoreID := range scoreIDs {
+		old, err := readScores(tx, "id = ?", scoreID)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to read score %s: %v", scoreID, err)
+		}
+		if len(old) == 0 {
+			continue
+		}
+		for _, query := range []string{"DELETE FROM scores WHERE id = ?", "DELETE FROM guesses WHERE score_id = ?", "DELETE FROM score_details WHERE score_id = ?"} {
+			_, err = tx.Exec(query, scoreID)
+			if err != nil {
+				tx.Rollback()
+				return fmt.Errorf("failed to delete score %s: %v", scoreID, err)
+			}
+		}
+		err = addAudit(tx, scoreID, &old[0], nil, source)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to audit score %s: %v", scoreID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Cache fetched channel info
+func (s *sqlStore) AddChannel(channel *discordgo.Channel) error {
+	stmt, err := s.db.Prepare(`
+		INSERT INTO channels (channel_id, guild_id, name)
+		VALUES (?, ?, ?)
+		ON CONFLICT (channel_id) DO UPDATE SET guild_id = excluded.guild_id, name = excluded.name
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(channel.ID, channel.GuildID, channel.Name)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Read cached channel info. Returns sql.ErrNoRows if the channel hasn't been seen.
+func (s *sqlStore) GetChannel(channelID string) (*discordgo.Channel, error) {
+	var channel discordgo.Channel
+	row := s.db.QueryRow("SELECT channel_id, guild_id, name FROM channels WHERE channel_id = ?", channelID)
+	err := row.Scan(&channel.ID, &channel.GuildID, &channel.Name)
+	if err != nil {
+		return nil, err
 	}
+	return &channel, nil
+}
+
+// Grab oldest and newest id. Used to download incrementally.
+func (s *sqlStore) GetScoreIDRange() (string, string, error) {
+	db := s.db
 	var oldest string
 	var newest string
-	err = db.QueryRow("SELECT MIN(id), MAX(id) FROM scores").Scan(&oldest, &newest)
+	err := db.QueryRow("SELECT MIN(message_id), MAX(message_id) FROM scores").Scan(&oldest, &newest)
 	if err != nil {
 		return "", "", err
 	} else {
@@ -137,13 +407,10 @@ func getScoreIDRange() (string, string, error) {
 }
 
 // Get most recent message ID for a specific channel
-func getMostRecentMessageID(channelID string) (string, error) {
-	db, err := getDatabase()
-	if err != nil {
-		return "", err
-	}
+func (s *sqlStore) GetMostRecentMessageID(channelID string) (string, error) {
+	db := s.db
 	var messageID string
-	err = db.QueryRow("SELECT MAX(id) FROM scores WHERE channel_id = ?", channelID).Scan(&messageID)
+	err := db.QueryRow("SELECT MAX(message_id) FROM scores WHERE channel_id = ?", channelID).Scan(&messageID)
 	if err == sql.ErrNoRows {
 		return "", nil
 	}
@@ -154,15 +421,12 @@ func getMostRecentMessageID(channelID string) (string, error) {
 }
 
 // Get latest scores
-func GetRecentScores() ([]Score, error) {
-	db, err := getDatabase()
-	if err != nil {
-		return nil, err
-	}
+func (s *sqlStore) GetRecentScores() ([]Score, error) {
+	db := s.db
 	sql := `
-		SELECT id, channel_id, username, game, game_number, score, win, hardmode
-		FROM scores
-		ORDER BY id DESC
+		SELECT s.id, s.message_id, s.channel_id, COALESCE(s.player_id, ''), s.username, COALESCE(pl.display_name, ''), s.game, s.game_number, s.game_number_text, s.score, s.score_text, s.win, s.hardmode
+		FROM scores s` + playerJoins + `
+		ORDER BY s.message_id DESC
 		LIMIT 5
 	`
 	rows, err := db.Query(sql)
@@ -174,10 +438,15 @@ func GetRecentScores() ([]Score, error) {
 		var score Score
 		err := rows.Scan(
 			&score.ID,
+			&score.MessageID,
 			&score.ChannelID,
+			&score.PlayerID,
 			&score.Username,
+			&score.Name,
 			&score.Game,
+			&score.Number,
 			&score.GameNumber,
+			&score.Value,
 			&score.Score,
 			&score.Win,
 			&score.Hardmode,
@@ -200,18 +469,15 @@ type SearchResult struct {
 }
 
 // Used by search box
-func findByChannelOrUsername(query string) ([]SearchResult, error) {
+func (s *sqlStore) FindByChannelOrUsername(query string) ([]SearchResult, error) {
 	if query == "" {
 		return nil, fmt.Errorf("search query is empty")
 	}
-	db, err := getDatabase()
-	if err != nil {
-		return nil, err
-	}
+	db := s.db
 	sql := `
-		SELECT "channel", channel_id, name 
+		SELECT 'channel', channel_id, name
 		FROM channels
-		WHERE name LIKE ?
+		WHERE LOWER(name) LIKE LOWER(?)
 		LIMIT 50
 	`
 	rows, err := db.Query(sql, "%"+query+"%")
@@ -238,12 +504,13 @@ func findByChannelOrUsername(query string) ([]SearchResult, error) {
 		return nil, err
 	}
 	sql = `
-		SELECT "user", username, username 
-		FROM (SELECT DISTINCT username FROM scores)
-		WHERE username LIKE ?
+		SELECT 'user', ` + playerKey + `, MAX(COALESCE(pl.display_name, s.username))
+		FROM scores s` + playerJoins + `
+		WHERE LOWER(s.username) LIKE LOWER(?) OR LOWER(pl.display_name) LIKE LOWER(?)
+		GROUP BY ` + playerKey + `
 		LIMIT 50
 	`
-	rows, err = db.Query(sql, "%"+query+"%")
+	rows, err = db.Query(sql, "%"+query+"%", "%"+query+"%")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get users: %v", err)
 	}
@@ -268,20 +535,18 @@ func findByChannelOrUsername(query string) ([]SearchResult, error) {
 	return results, nil
 }
 
-func getScoresByUser(game string, username string, from string, to string) ([]Score, error) {
-	db, err := getDatabase()
-	if err != nil {
-		return nil, err
-	}
+// Scores for a player, by Discord user ID (or username for scores without one)
+func (s *sqlStore) GetScoresByUser(game string, player string, from string, to string) ([]Score, error) {
+	db := s.db
 	sql := `
-		SELECT id, channel_id, username, s.game, s.game_number, score, win, hardmode
+		SELECT s.id, s.message_id, s.channel_id, COALESCE(s.player_id, ''), s.username, COALESCE(pl.display_name, ''), s.game, s.game_number, s.game_number_text, s.score, s.score_text, s.win, s.hardmode
 		FROM scores s
 		JOIN puzzles p
-			ON s.game = p.game AND s.game_number = p.game_number
-		WHERE s.game = ? AND s.username = ? AND p.date >= ? AND p.date <= ?
+			ON s.game = p.game AND s.game_number = p.game_number` + playerJoins + `
+		WHERE s.game = ? AND ` + playerKey + ` = ? AND p.date >= ? AND p.date <= ?
 		ORDER BY s.game_number DESC
 	`
-	rows, err := db.Query(sql, game, username, from, to)
+	rows, err := db.Query(sql, game, player, from, to)
 	if err != nil {
 		return nil, err
 	}
@@ -290,10 +555,15 @@ func getScoresByUser(game string, username string, from string, to string) ([]Sc
 		var score Score
 		err := rows.Scan(
 			&score.ID,
+			&score.MessageID,
 			&score.ChannelID,
+			&score.PlayerID,
 			&score.Username,
+			&score.Name,
 			&score.Game,
+			&score.Number,
 			&score.GameNumber,
+			&score.Value,
 			&score.Score,
 			&score.Win,
 			&score.Hardmode,
@@ -309,28 +579,27 @@ func getScoresByUser(game string, username string, from string, to string) ([]Sc
 	return scores, nil
 }
 
-// List usernames in same guild as user. Used for user dropdown.
-func getFriendNames(username string) ([]string, error) {
-	db, err := getDatabase()
-	if err != nil {
-		return nil, err
-	}
+// List players in the same channels as a player. Used for the player dropdown.
+func (s *sqlStore) GetFriends(player string) ([]Player, error) {
+	db := s.db
 	sql := `
-		SELECT DISTINCT username
-		FROM scores
-		WHERE channel_id IN (
-			SELECT DISTINCT channel_id
-			FROM scores
-			WHERE username = ?)
+		SELECT ` + playerKey + `, MAX(COALESCE(pl.display_name, s.username))
+		FROM scores s` + playerJoins + `
+		WHERE s.channel_id IN (
+			SELECT DISTINCT s.channel_id
+			FROM scores s` + playerJoins + `
+			WHERE ` + playerKey + ` = ?)
+		GROUP BY ` + playerKey + `
+		ORDER BY 2
 	`
-	rows, err := db.Query(sql, username)
+	rows, err := db.Query(sql, player)
 	if err != nil {
 		return nil, err
 	}
-	var friends []string
+	var friends []Player
 	for rows.Next() {
-		var friend string
-		err := rows.Scan(&friend)
+		var friend Player
+		err := rows.Scan(&friend.ID, &friend.Name)
 		if err != nil {
 			return nil, err
 		}
@@ -343,28 +612,27 @@ func getFriendNames(username string) ([]string, error) {
 }
 
 type AttendanceStats struct {
-	Username    string
+	PlayerID    string
+	Username    string // Display name
 	GamesPlayed int
 	DaysActive  int
 }
 
 // Get attendance statistics for a guild for a specific month - games played and days active per player
-func getAttendanceStatsForMonth(guildID string, month string) ([]AttendanceStats, error) {
-	db, err := getDatabase()
-	if err != nil {
-		return nil, err
-	}
+func (s *sqlStore) GetAttendanceStatsForMonth(guildID string, month string) ([]AttendanceStats, error) {
+	db := s.db
 	sql := `
-		SELECT 
-			s.username,
+		SELECT
+			` + playerKey + `,
+			MAX(COALESCE(pl.display_name, s.username)),
 			COUNT(s.id) as games_played,
-			COUNT(DISTINCT DATE(p.date)) as days_active
+			COUNT(DISTINCT p.date) as days_active
 		FROM scores s
 		JOIN puzzles p ON s.game = p.game AND s.game_number = p.game_number
-		JOIN channels c ON s.channel_id = c.channel_id
-		WHERE c.guild_id = ? AND strftime('%Y-%m', p.date) = ?
-		GROUP BY s.username
-		ORDER BY s.username
+		JOIN channels c ON s.channel_id = c.channel_id` + playerJoins + `
+		WHERE c.guild_id = ? AND SUBSTR(p.date, 1, 7) = ?
+		GROUP BY ` + playerKey + `
+		ORDER BY 2
 	`
 	rows, err := db.Query(sql, guildID, month)
 	if err != nil {
@@ -376,6 +644,7 @@ func getAttendanceStatsForMonth(guildID string, month string) ([]AttendanceStats
 	for rows.Next() {
 		var stat AttendanceStats
 		err := rows.Scan(
+			&stat.PlayerID,
 			&stat.Username,
 			&stat.GamesPlayed,
 			&stat.DaysActive,
@@ -101,7 +101,7 @@ func (dc *DiscordConnection) enableEchoCommand() (ccmd *discordgo.ApplicationCom
 }
 
 func (dc *DiscordConnection) enableStatsCommand() (ccmd *discordgo.ApplicationCommand, err error) {
-	games, _ := getGameList("", "", "", "")
+	games, _ := store.GetGameList("", "", "", "")
 	choices := []*discordgo.ApplicationCommandOptionChoice{}
 	if len(games) > 0 {
 		for _, game := range games {
@@ -138,17 +138,10 @@ func (dc *DiscordConnection) enableStatsCommand() (ccmd *discordgo.ApplicationCo
 				game = option.StringValue()
 			}
 		}
-		stats, err := getStats(game, i.GuildID, "", "")
-		var content string
-		if err != nil {
-			content = fmt.Sprintf("Error getting stats: %v", err)
-		} else {
-			content = SPrintStatsMarkdownDiscord(stats)
-		}
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: content,
+				Content: statsCommandContent(game, i.GuildID),
 			},
 		})
 	})
@@ -159,7 +152,7 @@ func (dc *DiscordConnection) enableStatsCommand() (ccmd *discordgo.ApplicationCo
 }
 
 func (dc *DiscordConnection) enableSeasonCommand() (ccmd *discordgo.ApplicationCommand, err error) {
-	games, _ := getGameList("", "", "", "")
+	games, _ := store.GetGameList("", "", "", "")
 	cmd := discordgo.ApplicationCommand{
 		Type:        discordgo.ChatApplicationCommand,
 		Name:        "season",
@@ -170,26 +163,10 @@ func (dc *DiscordConnection) enableSeasonCommand() (ccmd *discordgo.ApplicationC
 		return nil, err
 	}
 	dc.Session.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		content := ""
-		for _, game := range games {
-			stats, err := getStats(game, i.GuildID, "", "")
-			if err != nil {
-				s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionResponseData{
-						Content: err.Error(),
-					},
-				})
-				return
-			} else {
-				content = content + "# " + game + "\n"
-				content = content + SPrintStatsMarkdownDiscord(stats) + "\n"
-			}
-		}
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: content,
+				Content: seasonCommandContent(games, i.GuildID),
 			},
 		})
 	})
@@ -199,6 +176,38 @@ func (dc *DiscordConnection) enableSeasonCommand() (ccmd *discordgo.ApplicationC
 	return ccmd, err
 }
 
+// Reply to /stats
+func statsCommandContent(game string, guildID string) string {
+	stats, err := store.GetStats(game, guildID, "", "")
+	if err != nil {
+		return fmt.Sprintf("Error getting stats: %v", err)
+	}
+	content := SPrintStatsMarkdownDiscord(stats)
+	if game == "Connections" {
+		connectionsStats, err := store.GetConnectionsStats(guildID, "", "")
+		if err != nil {
+			return fmt.Sprintf("Error getting stats: %v", err)
+		} else if len(connectionsStats) > 0 {
+			content += "Fewest Mistakes\n" + SPrintConnectionsStatsMarkdownDiscord(connectionsStats)
+		}
+	}
+	return content
+}
+
+// Reply to /season
+func seasonCommandContent(games []string, guildID string) string {
+	content := ""
+	for _, game := range games {
+		stats, err := store.GetStats(game, guildID, "", "")
+		if err != nil {
+			return err.Error()
+		}
+		content = content + "# " + game + "\n"
+		content = content + SPrintStatsMarkdownDiscord(stats) + "\n"
+	}
+	return content
+}
+
 func (dc *DiscordConnection) enableSlashCommands() (err error) {
 	_, err = dc.enableStatsCommand()
 	if err != nil {
@@ -217,6 +226,7 @@ type Options struct {
 	Channel string // Discord Channel ID
 	Before  string // Backward search pointer
 	After   string // Forward search pointer
+	Source  string // Command doing the scan, for the audit log
 }
 
 // Fetch messages from Discord, parse for puzzles and save to DB
@@ -234,7 +244,12 @@ func (dc *DiscordConnection) scanChannel(options Options) error {
 	if err != nil {
 		return err
 	}
-	err = addScores(scores)
+	err = store.AddScores(scores, options.Source)
+	if err != nil {
+		return err
+	}
+	// Fill in player IDs for scores saved before they were recorded, including any that no longer parse
+	err = store.BackfillPlayerIDs(messages, options.Source)
 	if err != nil {
 		return err
 	}
@@ -258,23 +273,23 @@ func (dc *DiscordConnection) scanChannel(options Options) error {
 
 // Periodic channel scan to cover messages not received by websocket
 func (dc *DiscordConnection) channelTick(channel string) error {
-	before, after, err := getScoreIDRange()
+	before, after, err := store.GetScoreIDRange()
 	if err != nil {
 		return err
 	}
 	if before != "" && after != "" {
 		// Incremental load
-		err = dc.scanChannel(Options{Channel: channel, Before: before})
+		err = dc.scanChannel(Options{Channel: channel, Before: before, Source: sourceMonitor})
 		if err != nil {
 			return err
 		}
-		err = dc.scanChannel(Options{Channel: channel, After: after})
+		err = dc.scanChannel(Options{Channel: channel, After: after, Source: sourceMonitor})
 		if err != nil {
 			return err
 		}
 	} else {
 		// Fetch all
-		err = dc.scanChannel(Options{Channel: channel})
+		err = dc.scanChannel(Options{Channel: channel, Source: sourceMonitor})
 		if err != nil {
 			return err
 		}
@@ -311,17 +326,25 @@ func (dc *DiscordConnection) startDiscordMonitor() error {
 	logPrintln("Starting monitor...")
 	// Called when a message is created in a channel
 	dc.Session.AddHandler(func(s *discordgo.Session, m *discordgo.MessageCreate) {
-		score, err := ParseScoreFromMessage(m.Message)
+		scores, err := ParseScoreFromMessage(m.Message)
 		if err != nil {
 			logPrintln("Parser error: %v, %v", err, m)
-			return
+			err = addUnparsedMessage(m.Message, err, len(scores) > 0)
+			if err != nil {
+				logPrintln("addUnparsedMessage error: %v, %v", err, m)
+			}
+			if len(scores) == 0 {
+				return
+			}
 		}
-		err = addScores([]Score{*score})
+		err = store.AddScores(scores, sourceMonitor)
 		if err != nil {
 			logPrintln("addScores error: %v, %v", err, m)
 			return
 		}
-		logPrintln("Added score from bot: %s %s %s %s", score.Username, score.Game, score.GameNumber, score.Score)
+		for _, score := range scores {
+			logPrintln("Added score from bot: %s %s %s %s", score.Username, score.Game, score.GameNumber, score.Score)
+		}
 		dc.startChannelMonitor(m.ChannelID)
 	})
 	dc.onDiscordConnectionClose(func() error {
@@ -331,34 +354,13 @@ func (dc *DiscordConnection) startDiscordMonitor() error {
 	return nil
 }
 
-// Cache fetched channel info
-func storeChannelInfo(channel *discordgo.Channel) error {
-	db, err := getDatabase()
-	if err != nil {
-		return err
-	}
-	stmt, err := db.Prepare(`
-		INSERT OR REPLACE INTO channels (channel_id, guild_id, name)
-		VALUES (?, ?, ?)
-	`)
-	if err != nil {
-		return err
-	}
-	defer stmt.Close()
-	_, err = stmt.Exec(channel.ID, channel.GuildID, channel.Name)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
 // Grab channel info from Discord and save to database for later use
 func (dc *DiscordConnection) fetchChannelInfo(channelID string) (*discordgo.Channel, error) {
 	channel, err := dc.Session.Channel(channelID)
 	if err != nil {
 		return nil, err
 	}
-	err = storeChannelInfo(channel)
+	err = store.AddChannel(channel)
 	if err != nil {
 		return nil, err
 	}
@@ -369,26 +371,16 @@ func (dc *DiscordConnection) fetchChannelInfo(channelID string) (*discordgo.Chan
 //
 // Defaults to a local database. Falls back to querying Discord.
 func readChannelInfo(channelID string) (*discordgo.Channel, error) {
-	db, err := getDatabase()
-	if err != nil {
-		return nil, err
-	}
-	var channel discordgo.Channel
-	row := db.QueryRow("SELECT channel_id, guild_id, name FROM channels WHERE channel_id = ?", channelID)
-	err = row.Scan(&channel.ID, &channel.GuildID, &channel.Name)
+	channel, err := store.GetChannel(channelID)
 	if err == sql.ErrNoRows && discordConnection != nil {
-		fetched_channel, err := discordConnection.fetchChannelInfo(channelID)
-		return fetched_channel, err
-	} else if err != nil {
-		return nil, err
-	} else {
-		return &channel, nil
+		return discordConnection.fetchChannelInfo(channelID)
 	}
+	return channel, err
 }
 
 // Update all channels from their most recent entry forward
 func (dc *DiscordConnection) updateAllChannels() error {
-	channels, err := getChannelList()
+	channels, err := store.GetChannelList()
 	if err != nil {
 		return err
 	}
@@ -397,7 +389,7 @@ func (dc *DiscordConnection) updateAllChannels() error {
 		return nil
 	}
 	for _, channelID := range channels {
-		mostRecentID, err := getMostRecentMessageID(channelID)
+		mostRecentID, err := store.GetMostRecentMessageID(channelID)
 		if err != nil {
 			return fmt.Errorf("failed to get most recent message ID for channel %s: %v", channelID, err)
 		}
@@ -406,10 +398,30 @@ func (dc *DiscordConnection) updateAllChannels() error {
 			continue
 		}
 		logPrintln("Updating channel %s from message %s", channelID, mostRecentID)
-		err = dc.scanChannel(Options{Channel: channelID, After: mostRecentID})
+		err = dc.scanChannel(Options{Channel: channelID, After: mostRecentID, Source: sourceUpdate})
 		if err != nil {
 			return fmt.Errorf("failed to update channel %s: %v", channelID, err)
 		}
 	}
 	return nil
 }
+
+// Rescan channels with scores saved before player IDs were recorded
+func (dc *DiscordConnection) backfillPlayers() error {
+	channels, err := store.GetChannelsMissingPlayerIDs()
+	if err != nil {
+		return err
+	}
+	if len(channels) == 0 {
+		logPrintln("All scores have player IDs")
+		return nil
+	}
+	for _, channelID := range channels {
+		logPrintln("Rescanning channel %s for player IDs", channelID)
+		err = dc.scanChannel(Options{Channel: channelID, Source: sourceBackfill})
+		if err != nil {
+			return fmt.Errorf("failed to rescan channel %s: %v", channelID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Today's puzzle number, so slash commands include it in the current season
+func todaysPuzzle(t *testing.T, game string) int {
+	epoch, err := time.Parse("2006-01-02", gameInfos[game].Epoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return int(time.Now().UTC().Sub(epoch).Hours() / 24)
+}
+
+func TestStatsCommand(t *testing.T) {
+	useMemoryStore(t)
+	wordle := fmt.Sprintf("Wordle %d 4/6", todaysPuzzle(t, "Wordle"))
+	connections := fmt.Sprintf("Connections\nPuzzle #%d\n🟨🟨🟨🟨\n🟩🟩🟩🟩\n🟦🟦🟦🟦\n🟪🟪🟪🟪", todaysPuzzle(t, "Connections"))
+	addTestScores(t, map[string][]string{"bob": {wordle, connections}})
+	content := statsCommandContent("Wordle", "g1")
+	if !strings.Contains(content, "| bob") {
+		t.Errorf("expected bob in /stats:\n%s", content)
+	}
+	content = statsCommandContent("Connections", "g1")
+	if !strings.Contains(content, "Fewest Mistakes") {
+		t.Errorf("expected mistakes table in /stats:\n%s", content)
+	}
+	content = seasonCommandContent([]string{"Wordle", "Connections"}, "g1")
+	if !strings.Contains(content, "# Wordle") || !strings.Contains(content, "# Connections") {
+		t.Errorf("expected every game in /season:\n%s", content)
+	}
+}
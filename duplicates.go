@@ -0,0 +1,176 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Which score counts when a player posts the same puzzle more than once
+const (
+	duplicateFirst  = "first"  // First post wins (default)
+	duplicateBest   = "best"   // Best result counts
+	duplicateLatest = "latest" // Latest post counts
+)
+
+var duplicatePolicies = []string{duplicateFirst, duplicateBest, duplicateLatest}
+
+// Other scores for the same puzzle from the same player, with merged identities resolved as playerKey does.
+// Scores from before IDs were recorded match the player's username. Takes the score's ID, game, number,
+// Player() twice and username.
+const duplicateScores = `id != ? AND game = ? AND game_number = ? AND (
+		COALESCE((SELECT a.player_id FROM player_aliases a WHERE a.alias_id = COALESCE(scores.player_id, scores.username)), scores.player_id, scores.username)
+			= COALESCE((SELECT a.player_id FROM player_aliases a WHERE a.alias_id = ?), ?)
+		OR (scores.player_id IS NULL AND scores.username = ?))`
+
+// Check a policy name
+func validDuplicatePolicy(policy string) bool {
+	for _, valid := range duplicatePolicies {
+		if policy == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Duplicate policy for a guild
+func (s *sqlStore) GetDuplicatePolicy(guildID string) (string, error) {
+	return guildDuplicatePolicy(s.db, "SELECT value FROM guild_settings WHERE guild_id = ? AND name = 'duplicate_policy'", guildID)
+}
+
+// Set a guild's duplicate policy. Scores already saved are left as they are.
+func (s *sqlStore) SetDuplicatePolicy(guildID string, policy string) error {
+	if !validDuplicatePolicy(policy) {
+		return fmt.Errorf("duplicate policy must be one of %v, got %q", duplicatePolicies, policy)
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO guild_settings (guild_id, name, value)
+		VALUES (?, 'duplicate_policy', ?)
+		ON CONFLICT (guild_id, name) DO UPDATE SET value = excluded.value
+	`, guildID, policy)
+	return err
+}
+
+// Duplicate policy for the guild a channel belongs to
+func channelDuplicatePolicy(db dbtx, channelID string) (string, error) {
+	return guildDuplicatePolicy(db, `
+		SELECT g.value
+		FROM channels c
+		JOIN guild_settings g
+			ON g.guild_id = c.guild_id AND g.name = 'duplicate_policy'
+		WHERE c.channel_id = ?
+	`, channelID)
+}
+
+func guildDuplicatePolicy(db dbtx, query string, id string) (string, error) {
+	var policy string
+	err := db.QueryRow(query, id).Scan(&policy)
+	if err == sql.ErrNoRows || (err == nil && !validDuplicatePolicy(policy)) {
+		return duplicateFirst, nil
+	}
+	return policy, err
+}
+
+// Check if one message was posted before another. Snowflakes grow in length over time.
+func postedBefore(a string, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// Pick the score that counts for a puzzle. Ties on the best result go to the first post.
+func countedScore(policy string, scores []*Score) *Score {
+	counted := scores[0]
+	for _, score := range scores[1:] {
+		earlier := postedBefore(score.MessageID, counted.MessageID)
+		switch policy {
+		case duplicateLatest:
+			if !earlier {
+				counted = score
+			}
+		case duplicateBest:
+			better := score.Value < counted.Value
+			if getGameInfo(score.Game).HigherIsBetter {
+				better = score.Value > counted.Value
+			}
+			if better || (score.Value == counted.Value && earlier) {
+				counted = score
+			}
+		default:
+			if earlier {
+				counted = score
+			}
+		}
+	}
+	return counted
+}
+
+// Check if a repost has a different result, as opposed to sharing the same one again
+func conflictingRepost(a *Score, b *Score) bool {
+	return a.Value != b.Value || a.Win != b.Win
+}
+
+// Settle a score against the player's other posts of the same puzzle following the guild's policy. Reposts
+// that don't count are removed with deleteScore and those with a different result go to the review queue.
+// Returns whether the score counts and the reposts removed, for the caller to audit.
+func settleDuplicates(tx dbtx, score *Score, policy string, source string, deleteScore func(scoreID string) error) (bool, []Score, error) {
+	reposts, err := readScores(tx, duplicateScores, score.ID, score.Game, score.Number, score.Player(), score.Player(), score.Username)
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to read reposts %s: %v", score.ID, err)
+	}
+	counted := score
+	// Scores entered by hand always count
+	if source != sourceManual && len(reposts) > 0 {
+		candidates := []*Score{score}
+		for i := range reposts {
+			candidates = append(candidates, &reposts[i])
+		}
+		counted = countedScore(policy, candidates)
+	}
+	var removed []Score
+	for _, repost := range reposts {
+		if repost.ID == counted.ID {
+			continue
+		}
+		err = deleteScore(repost.ID)
+		if err != nil {
+			return false, nil, fmt.Errorf("failed to replace score %s: %v", repost.ID, err)
+		}
+		removed = append(removed, repost)
+	}
+	if counted != score {
+		if conflictingRepost(score, counted) {
+			err = flagRepost(tx, score, counted, policy)
+			if err != nil {
+				return false, nil, fmt.Errorf("failed to flag repost %s: %v", score.ID, err)
+			}
+		}
+		return false, removed, nil
+	}
+	if source != sourceManual {
+		for i := range removed {
+			if conflictingRepost(&removed[i], score) {
+				err = flagRepost(tx, &removed[i], score, policy)
+				if err != nil {
+					return false, nil, fmt.Errorf("failed to flag repost %s: %v", removed[i].ID, err)
+				}
+			}
+		}
+	}
+	return true, removed, nil
+}
+
+// Put a repost that doesn't count in the review queue. Reposts already reviewed keep their status, and a
+// message already queued for another reason, such as a share that didn't parse, keeps its entry.
+func flagRepost(tx dbtx, repost *Score, counted *Score, policy string) error {
+	_, err := tx.Exec(`
+		INSERT INTO unparsed (message_id, channel_id, player_id, username, content, error, status, reason)
+		VALUES (?, ?, ?, ?, ?, ?, 'pending', 'repost')
+		ON CONFLICT (message_id) DO UPDATE SET error = excluded.error
+		WHERE unparsed.reason = 'repost'
+	`,
+		repost.MessageID, repost.ChannelID, nullString(repost.PlayerID), repost.Username, repost.Content,
+		fmt.Sprintf("Repost of %s %s: %s counts instead (%s post policy)", repost.Game, repost.GameNumber, counted.Score, policy),
+	)
+	return err
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestDuplicatePolicy(t *testing.T) {
+	cases := []struct {
+		policy   string
+		expected string // Score that counts
+		flagged  int    // Reposts in the review queue
+	}{
+		{duplicateFirst, "4", 3},
+		{duplicateBest, "3", 3},
+		{duplicateLatest, "5", 2},
+	}
+	for _, item := range cases {
+		t.Run(item.policy, func(t *testing.T) {
+			useMemoryStore(t)
+			err := store.AddChannel(&discordgo.Channel{ID: "c1", GuildID: "g1", Name: "games"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = store.SetDuplicatePolicy("g1", item.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Saved out of order, as a backfill might
+			for _, post := range []struct {
+				n       int
+				content string
+			}{{1, "Wordle 1,327 3/6"}, {0, "Wordle 1,327 4/6"}, {2, "Wordle 1,327 5/6"}, {3, "Wordle 1,327 5/6"}} {
+				msg := &discordgo.Message{ID: testMessageID(post.n), ChannelID: "c1", Author: &discordgo.User{ID: "1", Username: "bob"}, Content: post.content}
+				scores, err := ParseScoreFromMessage(msg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				err = store.AddScores(scores, sourceRescan)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			scores, err := store.GetScoresByUser("Wordle", "1", testFrom, testTo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(scores) != 1 || scores[0].Score != item.expected {
+				t.Fatalf("expected only %s to count, got %+v", item.expected, scores)
+			}
+			flagged, err := store.GetUnparsedMessages("pending")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(flagged) != item.flagged || flagged[0].Reason != "repost" {
+				t.Errorf("expected %d reposts for review, got %+v", item.flagged, flagged)
+			}
+		})
+	}
+}
+
+func TestManualScoreCounts(t *testing.T) {
+	useMemoryStore(t)
+	addTestScores(t, map[string][]string{"bob": {"Wordle 1,327 4/6"}})
+	msg := &discordgo.Message{ID: testMessageID(5), ChannelID: "c1", Author: &discordgo.User{ID: "u-bob", Username: "bob"}, Content: "Wordle 1,327 2/6"}
+	scores, err := ParseScoreFromMessage(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.AddScores(scores, sourceRescan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.AddScores(scores, sourceManual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := store.GetScoresByUser("Wordle", "u-bob", testFrom, testTo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved[0].Score != "2" {
+		t.Errorf("expected the score entered by hand to count, got %+v", saved)
+	}
+}
+
+func TestUnknownDuplicatePolicy(t *testing.T) {
+	useMemoryStore(t)
+	if store.SetDuplicatePolicy("g1", "worst") == nil {
+		t.Error("expected an unknown policy to be refused")
+	}
+	policy, err := store.GetDuplicatePolicy("g1")
+	if err != nil || policy != duplicateFirst {
+		t.Errorf("expected first post wins by default, got %s: %v", policy, err)
+	}
+}
+
+func TestDuplicatesAcrossIdentities(t *testing.T) {
+	useMemoryStore(t)
+	addTestScores(t, map[string][]string{"bob": {"Wordle 1,327 4/6"}, "bobalt": {"Wordle 1,326 3/6"}})
+	err := store.MergePlayers("u-bobalt", "u-bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Saved before player IDs were recorded
+	legacy := Score{ID: scoreID(testMessageID(5), "Wordle"), MessageID: testMessageID(5), ChannelID: "c1", Username: "bob", Game: "Wordle", GameNumber: "1,328", Score: "5", Win: "Y"}
+	err = normalizeNumbers(&legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.AddScores([]Score{legacy}, sourceRescan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n, post := range map[int]struct{ id, content string }{6: {"u-bobalt", "Wordle 1,327 2/6"}, 7: {"u-bob", "Wordle 1,328 3/6"}} {
+		msg := &discordgo.Message{ID: testMessageID(n), ChannelID: "c1", Author: &discordgo.User{ID: post.id, Username: "bob"}, Content: post.content}
+		scores, err := ParseScoreFromMessage(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = store.AddScores(scores, sourceRescan)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	scores, err := readScores(store.(*sqlStore).db, "game_number >= ? ORDER BY game_number", 1327)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scores) != 2 || scores[0].Score != "4" || scores[1].Score != "5" {
+		t.Errorf("expected the first posts to count across the alt and the old username, got %+v", scores)
+	}
+}
+
+func TestRepostKeepsReviewEntry(t *testing.T) {
+	useMemoryStore(t)
+	addTestScores(t, map[string][]string{"bob": {"Wordle 1,327 4/6"}})
+	msg := &discordgo.Message{ID: testMessageID(5), ChannelID: "c1", Author: &discordgo.User{ID: "u-bob", Username: "bob"}, Content: "Wordle 1,327 2/6\nConnections ?"}
+	err := store.AddUnparsedMessage(UnparsedMessage{MessageID: msg.ID, ChannelID: "c1", PlayerID: "u-bob", Username: "bob", Content: msg.Content, Error: "no Connections result", Status: "pending", Reason: "partial"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scores, err := ParseScoreFromMessage(msg)
+	if len(scores) != 1 {
+		t.Fatalf("expected the Wordle share to parse, got %+v: %v", scores, err)
+	}
+	err = store.AddScores(scores, sourceRescan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := store.GetUnparsedMessage(msg.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Reason != "partial" || message.Error != "no Connections result" {
+		t.Errorf("expected the partial share left for review, got %+v", message)
+	}
+}
@@ -0,0 +1,639 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Data sets written by export, in the order import reads them back. Channels and players come before
+// scores so reposts are settled with the guild's duplicate policy and names are in place.
+var exportDatasets = []string{"channels", "players", "scores", "puzzles"}
+
+var exportFormats = []string{"jsonl", "csv"}
+
+// Scores are saved in batches so a large import doesn't sit in one transaction
+const importBatchSize = 500
+
+// Channel as exported
+type ChannelRecord struct {
+	ChannelID string `json:"channel_id"`
+	GuildID   string `json:"guild_id"`
+	Name      string `json:"name"`
+}
+
+// Puzzle as exported
+type PuzzleRecord struct {
+	Game       string `json:"game"`
+	GameNumber int    `json:"game_number"`
+	Date       string `json:"date"`
+	Solution   string `json:"solution"`
+}
+
+// Name a player has posted under, or an identity merged into another player. First and last seen are
+// the message IDs the name was seen on.
+type PlayerRecord struct {
+	PlayerID   string `json:"player_id"`
+	Username   string `json:"username"`
+	Name       string `json:"name"`
+	FirstSeen  string `json:"first_seen"`
+	LastSeen   string `json:"last_seen"`
+	MergedInto string `json:"merged_into"`
+	Merged     string `json:"merged"`
+}
+
+// Score as exported. The date is the puzzle's, for analysis, and is worked out again on import.
+type ScoreRecord struct {
+	ID         string            `json:"id"`
+	MessageID  string            `json:"message_id"`
+	ChannelID  string            `json:"channel_id"`
+	PlayerID   string            `json:"player_id"`
+	Username   string            `json:"username"`
+	Game       string            `json:"game"`
+	GameNumber string            `json:"game_number"`
+	Number     int               `json:"number"`
+	Date       string            `json:"date"`
+	Score      string            `json:"score"`
+	Value      int               `json:"value"`
+	Win        string            `json:"win"`
+	Hardmode   string            `json:"hardmode"`
+	Guesses    []GuessRecord     `json:"guesses"`
+	Details    map[string]string `json:"details"`
+	Content    string            `json:"content"`
+}
+
+type GuessRecord struct {
+	Board int    `json:"board"`
+	Row   int    `json:"row"`
+	Tiles string `json:"tiles"`
+}
+
+func newScoreRecord(score Score, date string) ScoreRecord {
+	record := ScoreRecord{
+		ID:         score.ID,
+		MessageID:  score.MessageID,
+		ChannelID:  score.ChannelID,
+		PlayerID:   score.PlayerID,
+		Username:   score.Username,
+		Game:       score.Game,
+		GameNumber: score.GameNumber,
+		Number:     score.Number,
+		Date:       date,
+		Score:      score.Score,
+		Value:      score.Value,
+		Win:        score.Win,
+		Hardmode:   score.Hardmode,
+		Details:    score.Details,
+		Content:    score.Content,
+	}
+	for _, guess := range score.Guesses {
+		record.Guesses = append(record.Guesses, GuessRecord(guess))
+	}
+	return record
+}
+
+// Score to save. Names are left out so the imported player names decide who is called what.
+func scoreFromRecord(record ScoreRecord) (Score, error) {
+	if record.MessageID == "" || record.Game == "" {
+		return Score{}, fmt.Errorf("score %q needs a message ID and game", record.ID)
+	}
+	if _, err := strconv.ParseInt(record.MessageID, 10, 64); err != nil {
+		return Score{}, fmt.Errorf("score %q has an invalid message ID: %v", record.ID, err)
+	}
+	if record.PlayerID == "" && record.Username == "" {
+		return Score{}, fmt.Errorf("score %q needs a player ID or username", record.ID)
+	}
+	score := Score{
+		ID:         scoreID(record.MessageID, record.Game),
+		MessageID:  record.MessageID,
+		ChannelID:  record.ChannelID,
+		PlayerID:   record.PlayerID,
+		Username:   record.Username,
+		Game:       record.Game,
+		GameNumber: record.GameNumber,
+		Number:     record.Number,
+		Score:      record.Score,
+		Value:      record.Value,
+		Win:        record.Win,
+		Hardmode:   record.Hardmode,
+		Details:    record.Details,
+		Content:    record.Content,
+	}
+	for _, guess := range record.Guesses {
+		score.Guesses = append(score.Guesses, Guess(guess))
+	}
+	return score, nil
+}
+
+// Every channel
+func (s *sqlStore) ExportChannels() ([]ChannelRecord, error) {
+	rows, err := s.db.Query("SELECT channel_id, COALESCE(guild_id, ''), COALESCE(name, '') FROM channels ORDER BY channel_id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to export channels: %v", err)
+	}
+	defer rows.Close()
+	var records []ChannelRecord
+	for rows.Next() {
+		var record ChannelRecord
+		err := rows.Scan(&record.ChannelID, &record.GuildID, &record.Name)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// Every puzzle
+func (s *sqlStore) ExportPuzzles() ([]PuzzleRecord, error) {
+	rows, err := s.db.Query("SELECT game, game_number, date, COALESCE(solution, '') FROM puzzles ORDER BY game, game_number")
+	if err != nil {
+		return nil, fmt.Errorf("failed to export puzzles: %v", err)
+	}
+	defer rows.Close()
+	var records []PuzzleRecord
+	for rows.Next() {
+		var record PuzzleRecord
+		err := rows.Scan(&record.Game, &record.GameNumber, &record.Date, &record.Solution)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// Every name in the player name history, then merged identities that have no names of their own (such
+// as usernames from before IDs were recorded)
+func (s *sqlStore) ExportPlayers() ([]PlayerRecord, error) {
+	rows, err := s.db.Query(`
+		SELECT n.player_id, n.username, n.display_name, n.first_seen, n.last_seen, COALESCE(a.player_id, ''), COALESCE(a.merged, '')
+		FROM player_names n
+		LEFT JOIN player_aliases a
+			ON a.alias_id = n.player_id
+		UNION ALL
+		SELECT a.alias_id, '', '', 0, 0, a.player_id, a.merged
+		FROM player_aliases a
+		WHERE NOT EXISTS (SELECT 1 FROM player_names n WHERE n.player_id = a.alias_id)
+		ORDER BY 1, 5
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export players: %v", err)
+	}
+	defer rows.Close()
+	var records []PlayerRecord
+	for rows.Next() {
+		var record PlayerRecord
+		var firstSeen, lastSeen int64
+		err := rows.Scan(&record.PlayerID, &record.Username, &record.Name, &firstSeen, &lastSeen, &record.MergedInto, &record.Merged)
+		if err != nil {
+			return nil, err
+		}
+		if firstSeen != 0 {
+			record.FirstSeen = strconv.FormatInt(firstSeen, 10)
+			record.LastSeen = strconv.FormatInt(lastSeen, 10)
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// Every score with its puzzle date, guesses and details
+func (s *sqlStore) ExportScores() ([]ScoreRecord, error) {
+	scores, err := readScores(s.db, "1 = 1 ORDER BY message_id, game")
+	if err != nil {
+		return nil, fmt.Errorf("failed to export scores: %v", err)
+	}
+	guesses := map[string][]Guess{}
+	rows, err := s.db.Query("SELECT score_id, board, row, tiles FROM guesses ORDER BY score_id, board, row")
+	if err != nil {
+		return nil, fmt.Errorf("failed to export guesses: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var guess Guess
+		err := rows.Scan(&id, &guess.Board, &guess.Row, &guess.Tiles)
+		if err != nil {
+			return nil, err
+		}
+		guesses[id] = append(guesses[id], guess)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	type puzzle struct {
+		game   string
+		number int
+	}
+	dates := map[puzzle]string{}
+	rows, err = s.db.Query("SELECT game, game_number, date FROM puzzles")
+	if err != nil {
+		return nil, fmt.Errorf("failed to export puzzles: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p puzzle
+		var date string
+		err := rows.Scan(&p.game, &p.number, &date)
+		if err != nil {
+			return nil, err
+		}
+		dates[p] = date
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	records := make([]ScoreRecord, 0, len(scores))
+	for _, score := range scores {
+		score.Guesses = guesses[score.ID]
+		records = append(records, newScoreRecord(score, dates[puzzle{score.Game, score.Number}]))
+	}
+	return records, nil
+}
+
+// Add channels, replacing their guild and name
+func (s *sqlStore) ImportChannels(records []ChannelRecord) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		_, err = tx.Exec(`
+			INSERT INTO channels (channel_id, guild_id, name)
+			VALUES (?, ?, ?)
+			ON CONFLICT (channel_id) DO UPDATE SET guild_id = excluded.guild_id, name = excluded.name
+		`, record.ChannelID, record.GuildID, record.Name)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to import channel %s: %v", record.ChannelID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Add puzzles. Dates already known are kept unless the import has an earlier one.
+func (s *sqlStore) ImportPuzzles(records []PuzzleRecord) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		_, err = tx.Exec(`
+			INSERT INTO puzzles (game, game_number, date, solution)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT (game, game_number) DO UPDATE
+			SET date = CASE WHEN excluded.date < puzzles.date THEN excluded.date ELSE puzzles.date END,
+				solution = COALESCE(puzzles.solution, excluded.solution)
+		`, record.Game, record.GameNumber, record.Date, nullString(record.Solution))
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to import puzzle %s %d: %v", record.Game, record.GameNumber, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Add player names and merges. Merges already in place, or of identities merged elsewhere, are kept.
+func (s *sqlStore) ImportPlayers(records []PlayerRecord) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if record.PlayerID == "" {
+			tx.Rollback()
+			return fmt.Errorf("player record needs a player ID")
+		}
+		if record.FirstSeen != "" {
+			for _, seen := range []string{record.FirstSeen, record.LastSeen} {
+				err = addPlayer(tx, record.PlayerID, record.Username, record.Name, seen)
+				if err != nil {
+					tx.Rollback()
+					return fmt.Errorf("failed to import player %s: %v", record.PlayerID, err)
+				}
+			}
+		}
+		if record.MergedInto != "" {
+			_, err = tx.Exec(`
+				INSERT INTO player_aliases (alias_id, player_id, merged)
+				VALUES (?, ?, ?)
+				ON CONFLICT (alias_id) DO NOTHING
+			`, record.PlayerID, record.MergedInto, record.Merged)
+			if err != nil {
+				tx.Rollback()
+				return fmt.Errorf("failed to import merge of %s: %v", record.PlayerID, err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// Write every data set to a directory, one file each
+func exportData(dir string, format string, out io.Writer) error {
+	if !validFormat(format) {
+		return fmt.Errorf("format must be one of %v, got %q", exportFormats, format)
+	}
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return err
+	}
+	for _, dataset := range exportDatasets {
+		path := filepath.Join(dir, dataset+"."+format)
+		count, err := exportDataset(dataset, path, format)
+		if err != nil {
+			return fmt.Errorf("failed to export %s: %v", dataset, err)
+		}
+		fmt.Fprintf(out, "%-10s %d rows to %s\n", dataset, count, path)
+	}
+	return nil
+}
+
+func exportDataset(dataset string, path string, format string) (int, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+	var count int
+	switch dataset {
+	case "channels":
+		count, err = writeExport(w, format, store.ExportChannels)
+	case "players":
+		count, err = writeExport(w, format, store.ExportPlayers)
+	case "puzzles":
+		count, err = writeExport(w, format, store.ExportPuzzles)
+	case "scores":
+		count, err = writeExport(w, format, store.ExportScores)
+	}
+	if err != nil {
+		return 0, err
+	}
+	err = w.Flush()
+	if err != nil {
+		return 0, err
+	}
+	return count, file.Close()
+}
+
+func writeExport[T any](w io.Writer, format string, export func() ([]T, error)) (int, error) {
+	records, err := export()
+	if err != nil {
+		return 0, err
+	}
+	return len(records), writeRecords(w, format, records)
+}
+
+// Read exported data back from a directory, or a single file named after its data set (such as
+// scores.csv). Running it again changes nothing, and reposts are settled the way AddScores settles them.
+func importData(path string, out io.Writer) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	var files []string
+	if info.IsDir() {
+		for _, dataset := range exportDatasets {
+			for _, format := range exportFormats {
+				file := filepath.Join(path, dataset+"."+format)
+				if _, err := os.Stat(file); err == nil {
+					files = append(files, file)
+				}
+			}
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no exported data in %s", path)
+		}
+	} else {
+		files = []string{path}
+	}
+	for _, file := range files {
+		dataset, format := datasetFromFile(file)
+		if !validDataset(dataset) || !validFormat(format) {
+			return fmt.Errorf("%s should be named after a data set %v with a format %v, such as scores.csv", file, exportDatasets, exportFormats)
+		}
+		count, err := importDataset(dataset, file, format)
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %v", file, err)
+		}
+		fmt.Fprintf(out, "%-10s %d rows from %s\n", dataset, count, file)
+	}
+	return nil
+}
+
+func importDataset(dataset string, path string, format string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	switch dataset {
+	case "channels":
+		records, err := readRecords[ChannelRecord](file, format)
+		if err != nil {
+			return 0, err
+		}
+		return len(records), store.ImportChannels(records)
+	case "players":
+		records, err := readRecords[PlayerRecord](file, format)
+		if err != nil {
+			return 0, err
+		}
+		return len(records), store.ImportPlayers(records)
+	case "puzzles":
+		records, err := readRecords[PuzzleRecord](file, format)
+		if err != nil {
+			return 0, err
+		}
+		return len(records), store.ImportPuzzles(records)
+	case "scores":
+		records, err := readRecords[ScoreRecord](file, format)
+		if err != nil {
+			return 0, err
+		}
+		scores := make([]Score, 0, len(records))
+		for _, record := range records {
+			score, err := scoreFromRecord(record)
+			if err != nil {
+				return 0, err
+			}
+			scores = append(scores, score)
+		}
+		for start := 0; start < len(scores); start += importBatchSize {
+			end := min(start+importBatchSize, len(scores))
+			err = store.AddScores(scores[start:end], sourceImport)
+			if err != nil {
+				return 0, err
+			}
+		}
+		return len(scores), nil
+	}
+	return 0, fmt.Errorf("unknown data set %s", dataset)
+}
+
+func validDataset(dataset string) bool {
+	for _, valid := range exportDatasets {
+		if dataset == valid {
+			return true
+		}
+	}
+	return false
+}
+
+func validFormat(format string) bool {
+	for _, valid := range exportFormats {
+		if format == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Data set and format from a file name such as scores.jsonl
+func datasetFromFile(path string) (string, string) {
+	name := filepath.Base(path)
+	format := strings.TrimPrefix(filepath.Ext(name), ".")
+	return strings.TrimSuffix(name, filepath.Ext(name)), format
+}
+
+// Columns for a record type, from its json tags
+func recordColumns(t reflect.Type) []string {
+	columns := make([]string, t.NumField())
+	for i := range columns {
+		columns[i] = t.Field(i).Tag.Get("json")
+	}
+	return columns
+}
+
+// Write records as JSON Lines, or CSV with a header. CSV cells hold text as is, and anything else
+// (numbers, guesses, details) as JSON.
+func writeRecords[T any](w io.Writer, format string, records []T) error {
+	if format == "jsonl" {
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		for _, record := range records {
+			err := encoder.Encode(record)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	columns := recordColumns(reflect.TypeFor[T]())
+	writer := csv.NewWriter(w)
+	err := writer.Write(columns)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		value := reflect.ValueOf(record)
+		row := make([]string, len(columns))
+		for i := range columns {
+			field := value.Field(i)
+			if field.Kind() == reflect.String {
+				row[i] = field.String()
+				continue
+			}
+			if field.IsZero() && field.Kind() != reflect.Int {
+				continue
+			}
+			data, err := json.Marshal(field.Interface())
+			if err != nil {
+				return err
+			}
+			row[i] = string(data)
+		}
+		err = writer.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Read records written by writeRecords. CSV columns can be in any order, and unknown ones are ignored.
+func readRecords[T any](r io.Reader, format string) ([]T, error) {
+	var records []T
+	if format == "jsonl" {
+		scanner := bufio.NewScanner(r)
+		// Long enough for a score with its whole message
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		line := 0
+		for scanner.Scan() {
+			line++
+			if strings.TrimSpace(scanner.Text()) == "" {
+				continue
+			}
+			var record T
+			err := json.Unmarshal(scanner.Bytes(), &record)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			records = append(records, record)
+		}
+		return records, scanner.Err()
+	}
+	t := reflect.TypeFor[T]()
+	kinds := map[string]reflect.Kind{}
+	for i, column := range recordColumns(t) {
+		kinds[column] = t.Field(i).Type.Kind()
+	}
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		// Rebuilt as a JSON object so cells decode the same way JSON Lines do
+		object := map[string]json.RawMessage{}
+		for i, column := range header {
+			kind, ok := kinds[column]
+			if !ok || i >= len(row) {
+				continue
+			}
+			if kind == reflect.String {
+				data, err := json.Marshal(row[i])
+				if err != nil {
+					return nil, err
+				}
+				object[column] = data
+			} else if row[i] != "" {
+				if !json.Valid([]byte(row[i])) {
+					return nil, fmt.Errorf("line %d: invalid %s %q", line, column, row[i])
+				}
+				object[column] = json.RawMessage(row[i])
+			}
+		}
+		data, err := json.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		var record T
+		err = json.Unmarshal(data, &record)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		records = append(records, record)
+	}
+}
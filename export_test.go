@@ -0,0 +1,153 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportImport(t *testing.T) {
+	for _, format := range exportFormats {
+		t.Run(format, func(t *testing.T) {
+			useMemoryStore(t)
+			connections := "Connections\nPuzzle #605\n🟨🟨🟨🟨\n🟩🟩🟩🟩\n🟦🟦🟦🟦\n🟪🟪🟪🟪"
+			addTestScores(t, map[string][]string{
+				"bob":  {"Wordle 1,327 4/6\n⬛🟨⬛⬛⬛\n🟩🟩🟩🟩🟩", connections},
+				"amy":  {"Wordle 1,327 3/6", "Wordle 1,328 5/6"},
+				"carl": {"Wordle 1,328 2/6"},
+			})
+			err := store.MergePlayers("u-carl", "u-bob")
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := t.TempDir()
+			var out strings.Builder
+			err = exportData(dir, format, &out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			exported := exportAll(t)
+			var details, guesses int
+			for _, score := range exported[2].([]ScoreRecord) {
+				details += len(score.Details)
+				guesses += len(score.Guesses)
+			}
+			if details == 0 || guesses == 0 {
+				t.Fatalf("expected guesses and details exported, got %+v", exported[2])
+			}
+
+			useMemoryStore(t)
+			for range 2 {
+				err = importData(dir, &out)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			imported := exportAll(t)
+			for i := range exported {
+				if !reflect.DeepEqual(exported[i], imported[i]) {
+					t.Errorf("expected %s imported as exported\nexported: %+v\nimported: %+v", exportDatasets[i], exported[i], imported[i])
+				}
+			}
+			// Importing twice only records the first
+			log, err := store.GetAuditLog(sourceImport, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(log) != 5 {
+				t.Errorf("expected 5 scores inserted by import, got %d", len(log))
+			}
+			player, err := store.GetPlayer("u-carl")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if player.ID != "u-bob" {
+				t.Errorf("expected carl merged into bob, got %+v", player)
+			}
+		})
+	}
+}
+
+// Every data set, in exportDatasets order
+func exportAll(t *testing.T) []any {
+	t.Helper()
+	channels, err := store.ExportChannels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	players, err := store.ExportPlayers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	scores, err := store.ExportScores()
+	if err != nil {
+		t.Fatal(err)
+	}
+	puzzles, err := store.ExportPuzzles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Merge times are kept, so only their presence is compared
+	for i := range players {
+		if players[i].Merged != "" {
+			players[i].Merged = "merged"
+		}
+	}
+	return []any{channels, players, scores, puzzles}
+}
+
+func TestImportSettlesReposts(t *testing.T) {
+	useMemoryStore(t)
+	addTestScores(t, map[string][]string{"bob": {"Wordle 1,327 4/6"}})
+	// The same puzzle posted again from another instance
+	file := filepath.Join(t.TempDir(), "scores.csv")
+	content := "message_id,channel_id,player_id,username,game,game_number,number,score,value,win,content\n" +
+		testMessageID(5) + ",c1,u-bob,bob,Wordle,\"1,327\",1327,2,2,Y,Wordle 1327 2/6\n"
+	err := os.WriteFile(file, []byte(content), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = importData(file, &strings.Builder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scores, err := store.GetScoresByUser("Wordle", "u-bob", testFrom, testTo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scores) != 1 || scores[0].Score != "4" {
+		t.Errorf("expected the first post to count, got %+v", scores)
+	}
+	flagged, err := store.GetUnparsedMessages("pending")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flagged) != 1 || flagged[0].MessageID != testMessageID(5) {
+		t.Errorf("expected the imported repost for review, got %+v", flagged)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	useMemoryStore(t)
+	dir := t.TempDir()
+	cases := map[string]string{
+		"notes.csv":    "a,b\n",
+		"scores.jsonl": "{\"message_id\": \"1\"}\n",
+		"puzzles.csv":  "game,game_number\nWordle,abc\n",
+	}
+	for name, content := range cases {
+		path := filepath.Join(dir, name)
+		err := os.WriteFile(path, []byte(content), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if importData(path, &strings.Builder{}) == nil {
+			t.Errorf("expected %s to be refused", name)
+		}
+	}
+	if importData(t.TempDir(), &strings.Builder{}) == nil {
+		t.Error("expected an empty directory to be refused")
+	}
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"time"
+)
+
+// Game defined in a config file instead of code. Handles simple "Name #N ... score" shares.
+//
+//	[
+//		{
+//			"name": "Lyricle",
+//			"pattern": "(?P<game>Lyricle) #(?P<game_no>\\d+) (?P<score>[1-6X])/6",
+//			"loss_scores": ["X"],
+//			"fail_penalty": "7"
+//		}
+//	]
+type GameDefinition struct {
+	Name         string            `json:"name"`
+	Pattern      string            `json:"pattern"`
+	Captures     map[string]string `json:"captures"`      // Score field (game, game_no, score, hardmode) to capture name
+	LossScores   []string          `json:"loss_scores"`   // Score values that count as a loss
+	LossContains string            `json:"loss_contains"` // Text that marks a share as a loss
+	FailPenalty  string            `json:"fail_penalty"`  // Score recorded for a loss
+	Direction    string            `json:"direction"`     // "lower" (default) or "higher" is better
+	Unit         string            `json:"unit"`          // What the score counts (default "guesses")
+	Epoch        string            `json:"epoch"`         // Date of puzzle 0 (YYYY-MM-DD) for daily puzzles
+}
+
+type configParser struct {
+	pattern
+	definition GameDefinition
+}
+
+func (p configParser) Name() string { return p.definition.Name }
+
+// Capture for a score field, honoring renamed captures
+func (p configParser) capture(captures map[string]string, field string) string {
+	return captures[captureName(p.definition, field)]
+}
+
+func (p configParser) Extract(content string) (*Score, error) {
+	captures, err := p.captures(content)
+	if err != nil {
+		return nil, err
+	}
+	game := p.capture(captures, "game")
+	if game == "" {
+		game = p.definition.Name
+	}
+	score := Score{
+		Game:       game,
+		GameNumber: p.capture(captures, "game_no"),
+		Hardmode:   p.capture(captures, "hardmode"),
+		Score:      p.capture(captures, "score"),
+		Win:        "Y",
+	}
+	if p.definition.LossContains != "" && strings.Contains(content, p.definition.LossContains) {
+		score.Win = "N"
+	}
+	return &score, nil
+}
+
+func (p configParser) Normalize(score *Score) error {
+	for _, loss := range p.definition.LossScores {
+		if score.Score == loss {
+			score.Win = "N"
+		}
+	}
+	if score.Win == "N" && p.definition.FailPenalty != "" {
+		score.Score = p.definition.FailPenalty
+	}
+	return nil
+}
+
+// Check a definition and build its parser
+func newConfigParser(definition GameDefinition) (*configParser, error) {
+	if definition.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if definition.Pattern == "" {
+		return nil, fmt.Errorf("pattern is required")
+	}
+	re, err := regexp.Compile(definition.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %v", err)
+	}
+	if definition.LossScores == nil {
+		definition.LossScores = []string{"X"}
+	}
+	for field, name := range definition.Captures {
+		switch field {
+		case "game", "game_no", "score", "hardmode":
+		default:
+			return nil, fmt.Errorf("unknown capture field %q", field)
+		}
+		if re.SubexpIndex(name) < 0 {
+			return nil, fmt.Errorf("pattern has no capture named %q", name)
+		}
+	}
+	for _, field := range []string{"game_no", "score"} {
+		name := captureName(definition, field)
+		if re.SubexpIndex(name) < 0 {
+			return nil, fmt.Errorf("pattern has no capture named %q", name)
+		}
+	}
+	// Loss scores that aren't numbers are saved as the penalty, so one the score can capture needs it
+	if definition.FailPenalty != "" {
+		_, err = parseNumber(definition.FailPenalty)
+		if err != nil {
+			return nil, fmt.Errorf("fail_penalty must be a number, got %q", definition.FailPenalty)
+		}
+	} else {
+		scoreCapture, err := captureRegexp(definition.Pattern, captureName(definition, "score"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %v", err)
+		}
+		for _, loss := range definition.LossScores {
+			if _, err := parseNumber(loss); err != nil && scoreCapture.MatchString(loss) {
+				return nil, fmt.Errorf("fail_penalty is required for loss score %q", loss)
+			}
+		}
+	}
+	switch definition.Direction {
+	case "", "lower", "higher":
+	default:
+		return nil, fmt.Errorf("direction must be \"lower\" or \"higher\", got %q", definition.Direction)
+	}
+	if definition.Epoch != "" {
+		_, err = time.Parse("2006-01-02", definition.Epoch)
+		if err != nil {
+			return nil, fmt.Errorf("epoch must be a date like 2021-06-19, got %q", definition.Epoch)
+		}
+	}
+	return &configParser{pattern: pattern{re: re}, definition: definition}, nil
+}
+
+// Capture name for a score field, honoring renamed captures
+func captureName(definition GameDefinition, field string) string {
+	if renamed, ok := definition.Captures[field]; ok {
+		return renamed
+	}
+	return field
+}
+
+// Regexp matching exactly what a named capture in a pattern can
+func captureRegexp(pattern string, name string) (*regexp.Regexp, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	var find func(re *syntax.Regexp) *syntax.Regexp
+	find = func(re *syntax.Regexp) *syntax.Regexp {
+		if re.Op == syntax.OpCapture && re.Name == name {
+			return re.Sub[0]
+		}
+		for _, sub := range re.Sub {
+			if found := find(sub); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	capture := find(re)
+	if capture == nil {
+		return nil, fmt.Errorf("pattern has no capture named %q", name)
+	}
+	return regexp.Compile(`^(?:` + capture.String() + `)$`)
+}
+
+// Line number for a byte offset
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// Read game definitions from JSON. Errors include the line of the offending definition.
+func parseGameDefinitions(filename string, data []byte) ([]*configParser, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	syntaxError := func(err error) error {
+		var jsonErr *json.SyntaxError
+		if errors.As(err, &jsonErr) {
+			return fmt.Errorf("%s:%d: %v", filename, lineAt(data, jsonErr.Offset), err)
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return fmt.Errorf("%s:%d: %v", filename, lineAt(data, dec.InputOffset()), err)
+	}
+	token, err := dec.Token()
+	if err != nil {
+		return nil, syntaxError(err)
+	}
+	if token != json.Delim('[') {
+		return nil, fmt.Errorf("%s:%d: expected a list of game definitions", filename, lineAt(data, dec.InputOffset()))
+	}
+	var parsers []*configParser
+	var errs []error
+	names := map[string]int{}
+	for dec.More() {
+		// Skip whitespace so the line points at the definition itself
+		offset := dec.InputOffset()
+		for offset < int64(len(data)) && strings.ContainsRune(" \t\r\n,", rune(data[offset])) {
+			offset++
+		}
+		line := lineAt(data, offset)
+		var definition GameDefinition
+		err := dec.Decode(&definition)
+		if err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				errs = append(errs, fmt.Errorf("%s:%d: %v", filename, line, err))
+				continue
+			}
+			return nil, syntaxError(err)
+		}
+		if previous, ok := names[definition.Name]; ok {
+			errs = append(errs, fmt.Errorf("%s:%d: %s is already defined on line %d", filename, line, definition.Name, previous))
+			continue
+		}
+		parser, err := newConfigParser(definition)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s:%d: %v", filename, line, err))
+			continue
+		}
+		names[definition.Name] = line
+		parsers = append(parsers, parser)
+	}
+	_, err = dec.Token()
+	if err != nil {
+		return nil, syntaxError(err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return parsers, nil
+}
+
+// Location of game definitions. Set MINDARI_GAMES to override.
+func gameDefinitionsPath() string {
+	path := os.Getenv("MINDARI_GAMES")
+	if path == "" {
+		path = "./games.json"
+	}
+	return path
+}
+
+// Load game definitions and merge them with the built-in parsers.
+// A definition with the same name as a built-in parser replaces it.
+// A missing file at the default location is not an error.
+func loadGameDefinitions() error {
+	path := gameDefinitionsPath()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && os.Getenv("MINDARI_GAMES") == "" {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	parsers, err := parseGameDefinitions(path, data)
+	if err != nil {
+		return err
+	}
+	for _, parser := range parsers {
+		replaced := false
+		for i, existing := range gameParsers {
+			if existing.Name() == parser.Name() {
+				gameParsers[i] = parser
+				replaced = true
+			}
+		}
+		if !replaced {
+			registerGameParser(parser)
+		}
+		unit := parser.definition.Unit
+		if unit == "" {
+			unit = "guesses"
+		}
+		registerGameInfo(parser.Name(), GameInfo{Unit: unit, HigherIsBetter: parser.definition.Direction == "higher", Epoch: parser.definition.Epoch})
+	}
+	logPrintln("Loaded %d game definitions from %s", len(parsers), path)
+	return nil
+}
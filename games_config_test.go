@@ -0,0 +1,121 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGameDefinitions(t *testing.T) {
+	data := []byte(`[
+	{
+		"name": "Bandle",
+		"pattern": "(?P<game>Bandle) #(?P<game_no>\\d+) (?P<score>[1-6X])/6",
+		"fail_penalty": "7"
+	},
+	{
+		"name": "Wordle Peaks",
+		"pattern": "(?P<game>Wordle Peaks) #(?P<game_no>\\d+) (?P<points>\\d+)",
+		"captures": {"score": "points"},
+		"loss_contains": "🟥",
+		"direction": "higher"
+	}
+]`)
+	parsers, err := parseGameDefinitions("games.json", data)
+	if err != nil {
+		t.Fatalf("parseGameDefinitions returned error: %v", err)
+	}
+	if len(parsers) != 2 {
+		t.Fatalf("parseGameDefinitions returned %d parsers, expected 2", len(parsers))
+	}
+	checkGameParser(t, parsers[0], []parserCase{
+		{
+			input:  "Bandle #597 3/6\n⬛️🟨🟩⬜️⬜️⬜️",
+			output: Score{Game: "Bandle", GameNumber: "597", Score: "3", Win: "Y"},
+		},
+		{
+			input:  "Bandle #598 X/6\n🟥🟥🟥🟥🟥🟥",
+			output: Score{Game: "Bandle", GameNumber: "598", Score: "7", Win: "N"},
+		},
+	})
+	checkGameParser(t, parsers[1], []parserCase{
+		{
+			input:  "Wordle Peaks #12 40",
+			output: Score{Game: "Wordle Peaks", GameNumber: "12", Score: "40", Win: "Y"},
+		},
+		{
+			input:  "Wordle Peaks #13 10 🟥",
+			output: Score{Game: "Wordle Peaks", GameNumber: "13", Score: "10", Win: "N"},
+		},
+	})
+}
+
+// Loss scores that are numbers are kept without a penalty
+func TestGameDefinitionLossWithoutPenalty(t *testing.T) {
+	parsers, err := parseGameDefinitions("games.json", []byte(`[
+	{
+		"name": "Tracks",
+		"pattern": "(?P<game>Tracks) #(?P<game_no>\\d+) (?P<score>\\d+) stops",
+		"loss_scores": ["0"]
+	}
+]`))
+	if err != nil {
+		t.Fatalf("parseGameDefinitions returned error: %v", err)
+	}
+	checkGameParser(t, parsers[0], []parserCase{
+		{
+			input:  "Tracks #40 0 stops",
+			output: Score{Game: "Tracks", GameNumber: "40", Score: "0", Win: "N"},
+		},
+	})
+}
+
+func TestGameDefinitionErrors(t *testing.T) {
+	cases := []struct {
+		data     string
+		expected []string
+	}{
+		{
+			data:     "[\n\t{\"name\": \"A\", \"pattern\": \"(?P<game_no>\\\\d+) (?P<score>\\\\d+)\"},\n\t{\"name\": \"B\", \"pattern\": \"(\"}\n]",
+			expected: []string{"games.json:3: invalid pattern"},
+		},
+		{
+			data:     "[\n\t{\"name\": \"A\", \"pattern\": \"(?P<game_no>\\\\d+)\"},\n\t{\"pattern\": \"x\"}\n]",
+			expected: []string{"games.json:2: pattern has no capture named \"score\"", "games.json:3: name is required"},
+		},
+		{
+			data:     "[\n\t{\"name\": \"A\",\n\t\"pattern\": 5}\n]",
+			expected: []string{"games.json:2: json: cannot unmarshal number"},
+		},
+		{
+			data:     "[\n\t{\"name\": \"A\"\n\t\"pattern\": \"x\"}\n]",
+			expected: []string{"games.json:3: invalid character"},
+		},
+		{
+			data:     "[\n\t{\"name\": \"A\", \"regex\": \"x\"}\n]",
+			expected: []string{"games.json:2: json: unknown field \"regex\""},
+		},
+		{
+			data:     "[\n\t{\"name\": \"A\", \"pattern\": \"(?P<game_no>\\\\d+) (?P<score>\\\\d+)\", \"epoch\": \"June 19\"}\n]",
+			expected: []string{"games.json:2: epoch must be a date"},
+		},
+		{
+			data:     "[\n\t{\"name\": \"A\", \"pattern\": \"(?P<game_no>\\\\d+) (?P<score>[1-6X])/6\"}\n]",
+			expected: []string{"games.json:2: fail_penalty is required for loss score \"X\""},
+		},
+		{
+			data:     "[\n\t{\"name\": \"A\", \"pattern\": \"(?P<game_no>\\\\d+) (?P<score>[1-6X])/6\", \"fail_penalty\": \"seven\"}\n]",
+			expected: []string{"games.json:2: fail_penalty must be a number"},
+		},
+	}
+	for _, item := range cases {
+		_, err := parseGameDefinitions("games.json", []byte(item.data))
+		if err == nil {
+			t.Fatalf("parseGameDefinitions should fail:\n%s", item.data)
+		}
+		for _, expected := range item.expected {
+			if !strings.Contains(err.Error(), expected) {
+				t.Fatalf("parseGameDefinitions error\n%v\ndoes not contain\n%s", err, expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Geography games
+
+// Tradle, Worldle and Flagle all use the "x/6" format, with distance squares for each guess
+type geoParser struct {
+	pattern
+	name string
+}
+
+func (p geoParser) Name() string { return p.name }
+
+func (p geoParser) Extract(content string) (*Score, error) {
+	score, _, err := p.extract(content)
+	if err != nil {
+		return nil, err
+	}
+	score.Guesses = parseGrid(content, 1)
+	return score, nil
+}
+
+func (geoParser) Normalize(score *Score) error {
+	normalizeFailPenalty(score, "7")
+	return nil
+}
+
+type globleParser struct{ pattern }
+
+func (globleParser) Name() string { return "Globle" }
+
+// Score is the number of guesses. Globle has no puzzle number, so the date is used instead.
+func (p globleParser) Extract(content string) (*Score, error) {
+	_, captures, err := p.extract(content)
+	if err != nil {
+		return nil, err
+	}
+	date, err := time.Parse("Jan 2, 2006", captures["date"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid Globle date: %v", err)
+	}
+	score := Score{
+		Game:       "Globle",
+		GameNumber: date.Format("20060102"),
+		Score:      captures["score"],
+	}
+	return &score, nil
+}
+
+func (globleParser) Normalize(score *Score) error {
+	score.Win = "Y"
+	return nil
+}
+
+type travleParser struct{ pattern }
+
+func (travleParser) Name() string { return "Travle" }
+
+var travleGuesses = regexp.MustCompile(`[✅🟩🟨🟧🟥⬛]`)
+
+// Score is the number of guesses. A loss uses every guess allowed, so like the ordle penalty it scores one
+// more for each country still to go. Regional versions (#travle_usa) are tracked as their own game.
+//
+// Details:
+//   - overage: guesses over the shortest route, for a win
+//   - away: countries left to go, for a loss
+func (p travleParser) Extract(content string) (*Score, error) {
+	score, captures, err := p.extract(content)
+	if err != nil {
+		return nil, err
+	}
+	score.Game = "Travle"
+	if captures["region"] != "" {
+		score.Game += " " + strings.ToUpper(captures["region"])
+	}
+	guesses := len(travleGuesses.FindAllString(content, -1))
+	if captures["overage"] != "" {
+		score.Win = "Y"
+		score.Details = map[string]string{"overage": captures["overage"]}
+	} else {
+		away, err := strconv.Atoi(captures["away"])
+		if err != nil {
+			return nil, err
+		}
+		guesses += away
+		score.Win = "N"
+		score.Details = map[string]string{"away": captures["away"]}
+	}
+	score.Score = strconv.Itoa(guesses)
+	return score, nil
+}
+
+func (travleParser) Normalize(score *Score) error {
+	return nil
+}
+
+func init() {
+	registerGameParser(geoParser{newPattern(`(?s)(?P<game>Tradle) #(?P<game_no>\d+).*(?P<score>[123456X])\/6`), "Tradle"})
+	registerGameParser(geoParser{newPattern(`(?s)(?P<game>Worldle) #(?P<game_no>\d+).*?(?P<score>[123456X])\/6`), "Worldle"})
+	registerGameParser(geoParser{newPattern(`(?s)(?P<game>Flagle) #(?P<game_no>\d+).*?(?P<score>[123456X])\/6`), "Flagle"})
+	registerGameParser(globleParser{newPattern(`(?s)🌎 (?P<date>[A-Z][a-z]{2} \d{1,2}, \d{4}) 🌍.*?= (?P<score>\d+)`)})
+	registerGameParser(travleParser{newPattern(`(?s)#travle(?:_(?P<region>[a-z]+))? #(?P<game_no>\d+) (?:\+(?P<overage>\d+)|\((?P<away>\d+) away\))`)})
+}
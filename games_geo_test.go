@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestTradleParser(t *testing.T) {
+	parser := getGameParser("Tradle")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "#Tradle #527 2/6 🟩🟩🟩🟩🟨 🟩🟩🟩🟩🟩 https://oec.world/en/tradle",
+			output: Score{Game: "Tradle", GameNumber: "527", Score: "2", Win: "Y"},
+		},
+		{
+			input:  "#Tradle #1072 X/6 🟩🟩🟩🟨⬜ 🟩🟩🟨⬜⬜ 🟩🟩🟩⬜⬜ 🟩🟩🟩🟩⬜ 🟩🟩🟩⬜⬜ 🟩🟩🟩⬜⬜",
+			output: Score{Game: "Tradle", GameNumber: "1072", Score: "7", Win: "N"},
+		},
+	})
+	checkGameParserRejects(t, parser, []string{
+		"Tradle #527 is tough",
+	})
+}
+
+func TestWorldleParser(t *testing.T) {
+	parser := getGameParser("Worldle")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "#Worldle #1234 (18.10.2026) 3/6 (100%)\n🟩🟩🟩🟨⬜↗️\n🟩🟩🟩🟩🟨↖️\n🟩🟩🟩🟩🟩🎉\nhttps://worldle.teuteuf.fr",
+			output: Score{Game: "Worldle", GameNumber: "1234", Score: "3", Win: "Y", Guesses: []Guess{{1, 1, "GGGY-"}, {1, 2, "GGGGY"}, {1, 3, "GGGGG"}}},
+		},
+		{
+			input:  "#Worldle #1235 (19.10.2026) X/6 (94%)\n🟩🟨⬜⬜⬜⬅️\n🟩🟩🟨⬜⬜⬆️\n🟩🟩🟨⬜⬜⬆️\n🟩🟩🟩⬜⬜↗️\n🟩🟩🟩🟨⬜↗️\n🟩🟩🟩🟩🟨↗️\nhttps://worldle.teuteuf.fr",
+			output: Score{Game: "Worldle", GameNumber: "1235", Score: "7", Win: "N"},
+		},
+	})
+	checkGameParserRejects(t, parser, []string{
+		"#Tradle #527 2/6",
+	})
+}
+
+func TestGlobleParser(t *testing.T) {
+	parser := getGameParser("Globle")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "🌎 Oct 18, 2026 🌍\n🔥 3 | Avg. Guesses: 5.2\n🟨🟧🟥🟥 = 4\n\nhttps://globle-game.com\n#globle",
+			output: Score{Game: "Globle", GameNumber: "20261018", Score: "4", Win: "Y"},
+		},
+	})
+}
+
+func TestTravleParser(t *testing.T) {
+	parser := getGameParser("Travle")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "#travle #482 +2\n🟧🟩✅✅✅\nhttps://travle.earth",
+			output: Score{Game: "Travle", GameNumber: "482", Score: "5", Win: "Y", Details: map[string]string{"overage": "2"}},
+		},
+		{
+			input:  "#travle_usa #120 +0 (Perfect)\n✅✅✅\nhttps://travle.earth/usa",
+			output: Score{Game: "Travle USA", GameNumber: "120", Score: "3", Win: "Y", Details: map[string]string{"overage": "0"}},
+		},
+		{
+			input:  "#travle #483 (4 away)\n🟥🟧🟥🟧🟥🟧🟧🟥🟥\nhttps://travle.earth",
+			output: Score{Game: "Travle", GameNumber: "483", Score: "13", Win: "N", Details: map[string]string{"away": "4"}},
+		},
+		{
+			input:  "#travle_usa #121 (1 away)\n🟩🟧🟥🟥🟧🟥\nhttps://travle.earth/usa",
+			output: Score{Game: "Travle USA", GameNumber: "121", Score: "7", Win: "N", Details: map[string]string{"away": "1"}},
+		},
+	})
+}
+
+func TestFlagleParser(t *testing.T) {
+	parser := getGameParser("Flagle")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "#Flagle #1234 (18.10.2026) 2/6\n🟥🟩🟥\n🟩🟩🟩\n🟩🟩🟩\n🟩🟩🟩\nhttps://www.flagle.io",
+			output: Score{Game: "Flagle", GameNumber: "1234", Score: "2", Win: "Y"},
+		},
+		{
+			input:  "#Flagle #1235 (19.10.2026) X/6\nhttps://www.flagle.io",
+			output: Score{Game: "Flagle", GameNumber: "1235", Score: "7", Win: "N"},
+		},
+	})
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LinkedIn games
+
+var linkedinBacktracks = regexp.MustCompile(`With (no|\d+) backtracks?`)
+
+// Zip, Queens, Tango, Crossclimb and Mini Sudoku share the same "Game #N | m:ss" format
+//
+// Details:
+//   - flawless: Y if solved without a mistake
+//   - backtracks: number of backtracks, when the game reports them
+type linkedinTimeParser struct {
+	pattern
+	name string
+}
+
+func (p linkedinTimeParser) Name() string { return p.name }
+
+func (p linkedinTimeParser) Extract(content string) (*Score, error) {
+	score, _, err := p.extract(content)
+	if err != nil {
+		return nil, err
+	}
+	score.Score, err = parseSolveTime(content)
+	if err != nil {
+		return nil, err
+	}
+	score.Details = map[string]string{"flawless": yesNo(strings.Contains(content, "flawless"))}
+	match := linkedinBacktracks.FindStringSubmatch(content)
+	if match != nil {
+		backtracks := match[1]
+		if backtracks == "no" {
+			backtracks = "0"
+		}
+		score.Details["backtracks"] = backtracks
+	}
+	return score, nil
+}
+
+func (linkedinTimeParser) Normalize(score *Score) error {
+	score.Win = "Y"
+	return nil
+}
+
+type pinpointParser struct{ pattern }
+
+func (pinpointParser) Name() string { return "Pinpoint" }
+
+// Guesses in the emoji row (🤔 🤔 📌 ⬜ ⬜) of a share without the guess count
+var pinpointRow = regexp.MustCompile(`[🤔📌](?: ?[🤔📌⬜])*`)
+
+// Score is the number of guesses, out of 5. Missing the category counts as 6.
+func (p pinpointParser) Extract(content string) (*Score, error) {
+	score, _, err := p.extract(content)
+	if err != nil {
+		return nil, err
+	}
+	if score.Score == "" {
+		score.Score = strconv.Itoa(strings.Count(pinpointRow.FindString(content), "🤔") + 1)
+	}
+	if !strings.Contains(content, "📌") && !strings.Contains(content, "100% match") {
+		score.Score = "X"
+	}
+	return score, nil
+}
+
+func (pinpointParser) Normalize(score *Score) error {
+	normalizeFailPenalty(score, "6")
+	return nil
+}
+
+// Date of puzzle 0 for LinkedIn games, which number one puzzle a day from launch
+var linkedinEpochs = map[string]string{
+	"Queens":     "2024-04-29",
+	"Crossclimb": "2024-04-29",
+	"Pinpoint":   "2024-04-29",
+	"Tango":      "2024-10-07",
+	"Zip":        "2025-03-17",
+}
+
+func init() {
+	for _, name := range []string{"Zip", "Queens", "Tango", "Crossclimb", "Mini Sudoku"} {
+		registerGameParser(linkedinTimeParser{newPattern(`(?s)(?P<game>` + name + `) #(?P<game_no>\d+).*`), name})
+		registerGameInfo(name, GameInfo{Unit: "seconds", Epoch: linkedinEpochs[name]})
+	}
+	registerGameParser(pinpointParser{newPattern(`(?s)(?P<game>Pinpoint) #(?P<game_no>\d+)(?: \| (?P<score>\d+) guess(?:es)?|\s+[🤔📌])`)})
+	registerGameInfo("Pinpoint", GameInfo{Unit: "guesses", Epoch: linkedinEpochs["Pinpoint"]})
+}
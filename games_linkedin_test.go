@@ -0,0 +1,120 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestZipParser(t *testing.T) {
+	parser := getGameParser("Zip")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Zip #175 | 0:11 🏁\nWith 1 backtrack 🛑\nlnkd.in/zip.",
+			output: Score{Game: "Zip", GameNumber: "175", Score: "11", Win: "Y"},
+		},
+		{
+			input:  "Zip #176 | 1:43 🏁\nlnkd.in/zip.",
+			output: Score{Game: "Zip", GameNumber: "176", Score: "103", Win: "Y"},
+		},
+	})
+	checkGameParserRejects(t, parser, []string{
+		"Mini Sudoku #28 | 0:54 and flawless ✏️",
+	})
+}
+
+func TestMiniSudokuParser(t *testing.T) {
+	parser := getGameParser("Mini Sudoku")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Mini Sudoku #31 | 3:16 and flawless ✏️\nThe classic game, made mini. Handcrafted by the originators of \"Sudoku.\"\nlnkd.in/minisudoku.",
+			output: Score{Game: "Mini Sudoku", GameNumber: "31", Score: "196", Win: "Y"},
+		},
+	})
+	checkGameParserRejects(t, parser, []string{
+		"Zip #176 | 1:43 🏁",
+	})
+	_, err := parseGame(parser, "Mini Sudoku #31 | gave up")
+	if err == nil {
+		t.Fatalf("Mini Sudoku without a time should not parse")
+	}
+}
+
+func TestLinkedinMarkers(t *testing.T) {
+	checkGameParser(t, getGameParser("Zip"), []parserCase{
+		{
+			input:  "Zip #175 | 0:11 🏁\nWith 1 backtrack 🛑\nlnkd.in/zip.",
+			output: Score{Game: "Zip", GameNumber: "175", Score: "11", Win: "Y", Details: map[string]string{"flawless": "N", "backtracks": "1"}},
+		},
+		{
+			input:  "Zip #176 | 0:10 and flawless 🏁\nWith no backtracks 🟢\nlnkd.in/zip.",
+			output: Score{Game: "Zip", GameNumber: "176", Score: "10", Win: "Y", Details: map[string]string{"flawless": "Y", "backtracks": "0"}},
+		},
+		{
+			input:  "Zip #177 | 0:25 🏁\nWith 12 backtracks 🛑\nlnkd.in/zip.",
+			output: Score{Game: "Zip", GameNumber: "177", Score: "25", Win: "Y", Details: map[string]string{"flawless": "N", "backtracks": "12"}},
+		},
+	})
+}
+
+func TestQueensParser(t *testing.T) {
+	parser := getGameParser("Queens")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Queens #123 | 1:23 and flawless 👑\nFirst 👑s: 🟦 🟩 🟨\nlnkd.in/queens.",
+			output: Score{Game: "Queens", GameNumber: "123", Score: "83", Win: "Y", Details: map[string]string{"flawless": "Y"}},
+		},
+		{
+			input:  "Queens #124 | 2:05 👑\nlnkd.in/queens.",
+			output: Score{Game: "Queens", GameNumber: "124", Score: "125", Win: "Y", Details: map[string]string{"flawless": "N"}},
+		},
+	})
+}
+
+func TestTangoParser(t *testing.T) {
+	parser := getGameParser("Tango")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Tango #45 | 0:55 and flawless 🌗\nlnkd.in/tango.",
+			output: Score{Game: "Tango", GameNumber: "45", Score: "55", Win: "Y", Details: map[string]string{"flawless": "Y"}},
+		},
+	})
+}
+
+func TestCrossclimbParser(t *testing.T) {
+	parser := getGameParser("Crossclimb")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Crossclimb #200 | 1:30 🪜\nlnkd.in/crossclimb.",
+			output: Score{Game: "Crossclimb", GameNumber: "200", Score: "90", Win: "Y", Details: map[string]string{"flawless": "N"}},
+		},
+	})
+}
+
+func TestPinpointParser(t *testing.T) {
+	parser := getGameParser("Pinpoint")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Pinpoint #180 | 3 guesses\n1️⃣ | 1% match\n2️⃣ | 5% match\n3️⃣ | 100% match 📌\nlnkd.in/pinpoint.",
+			output: Score{Game: "Pinpoint", GameNumber: "180", Score: "3", Win: "Y"},
+		},
+		{
+			input:  "Pinpoint #181 | 1 guess\n1️⃣ | 100% match 📌\nlnkd.in/pinpoint.",
+			output: Score{Game: "Pinpoint", GameNumber: "181", Score: "1", Win: "Y"},
+		},
+		{
+			input:  "Pinpoint #182 | 5 guesses\n1️⃣ | 1% match\n2️⃣ | 2% match\n3️⃣ | 9% match\n4️⃣ | 12% match\n5️⃣ | 40% match\nlnkd.in/pinpoint.",
+			output: Score{Game: "Pinpoint", GameNumber: "182", Score: "6", Win: "N"},
+		},
+		{
+			input:  "Pinpoint #183\n🤔 🤔 📌 ⬜ ⬜\nlnkd.in/pinpoint.",
+			output: Score{Game: "Pinpoint", GameNumber: "183", Score: "3", Win: "Y"},
+		},
+		{
+			input:  "Pinpoint #184\n🤔 🤔 🤔 🤔 🤔\nlnkd.in/pinpoint.",
+			output: Score{Game: "Pinpoint", GameNumber: "184", Score: "6", Win: "N"},
+		},
+	})
+	checkGameParserRejects(t, parser, []string{
+		"Zip #175 | 0:11 🏁",
+		"Pinpoint #185 was hard",
+	})
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Movie and music guessing games
+
+// One tile per attempt: 🟥 (wrong), 🟨 (close), ⬛ (skipped) or 🟩 (correct). Attempts left after the
+// answer are ⬜, except in Framed where they are ⬛ too, so counting has to stop at the first 🟩.
+var mediaAttempts = regexp.MustCompile(`[🟥🟨⬛🟩]`)
+
+// Framed, Heardle, Bandle and Moviedle share a strip of attempts with a fixed length
+//
+// Score is the attempt with the right answer. Misses count as one more than the number of attempts.
+type mediaParser struct {
+	pattern
+	name     string
+	attempts int
+}
+
+func (p mediaParser) Name() string { return p.name }
+
+func (p mediaParser) Extract(content string) (*Score, error) {
+	score, _, err := p.extract(content)
+	if err != nil {
+		return nil, err
+	}
+	score.Game = p.name
+	// Date numbered puzzles (2024-10-18) are stored as YYYYMMDD
+	score.GameNumber = strings.ReplaceAll(score.GameNumber, "-", "")
+	score.Score = "X"
+	for i, tile := range mediaAttempts.FindAllString(content, p.attempts) {
+		if tile == "🟩" {
+			score.Score = strconv.Itoa(i + 1)
+			break
+		}
+	}
+	return score, nil
+}
+
+func (p mediaParser) Normalize(score *Score) error {
+	normalizeFailPenalty(score, strconv.Itoa(p.attempts+1))
+	return nil
+}
+
+func init() {
+	registerGameParser(mediaParser{newPattern(`(?s)(?P<game>Framed) #(?P<game_no>\d+)`), "Framed", 6})
+	registerGameParser(mediaParser{newPattern(`(?s)#(?P<game>Heardle) #(?P<game_no>\d+)`), "Heardle", 6})
+	registerGameParser(mediaParser{newPattern(`(?s)(?P<game>Bandle) #(?P<game_no>\d+)`), "Bandle", 6})
+	registerGameParser(mediaParser{newPattern(`(?s)#(?P<game>Moviedle) #(?P<game_no>\d{4}-\d{2}-\d{2})`), "Moviedle", 6})
+}
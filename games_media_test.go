@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestFramedParser(t *testing.T) {
+	parser := getGameParser("Framed")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Framed #1234\n🎥 🟥 🟥 🟩 ⬛ ⬛ ⬛\n\nhttps://framed.wtf",
+			output: Score{Game: "Framed", GameNumber: "1234", Score: "3", Win: "Y"},
+		},
+		{
+			input:  "Framed #1235\n🎥 🟥 🟥 🟥 🟥 🟥 🟥\n\nhttps://framed.wtf",
+			output: Score{Game: "Framed", GameNumber: "1235", Score: "7", Win: "N"},
+		},
+	})
+}
+
+func TestHeardleParser(t *testing.T) {
+	parser := getGameParser("Heardle")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "#Heardle #123\n\n🔉🟥⬛️🟩⬜️⬜️⬜️\n\n#Heardle",
+			output: Score{Game: "Heardle", GameNumber: "123", Score: "3", Win: "Y"},
+		},
+		{
+			input:  "#Heardle #124\n\n🔇🟥🟥🟥🟥🟥🟥\n\n#Heardle",
+			output: Score{Game: "Heardle", GameNumber: "124", Score: "7", Win: "N"},
+		},
+	})
+}
+
+func TestBandleParser(t *testing.T) {
+	parser := getGameParser("Bandle")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Bandle #597 3/6\n⬛️🟨🟩⬜️⬜️⬜️\nFound: 12/16 (75%)\n#Bandle #Heardle #Wordle",
+			output: Score{Game: "Bandle", GameNumber: "597", Score: "3", Win: "Y"},
+		},
+		{
+			input:  "Bandle #598 x/6\n🟥🟥🟨🟥🟨🟥\n#Bandle",
+			output: Score{Game: "Bandle", GameNumber: "598", Score: "7", Win: "N"},
+		},
+	})
+}
+
+func TestMoviedleParser(t *testing.T) {
+	parser := getGameParser("Moviedle")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "#Moviedle #2024-10-18 \n\n 🎥 🟥 🟥 🟩 ⬜️ ⬜️ ⬜️ \n\n https://likewise.com/games/moviedle/2024-10-18",
+			output: Score{Game: "Moviedle", GameNumber: "20241018", Score: "3", Win: "Y"},
+		},
+	})
+}
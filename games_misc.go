@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Games that don't belong to a larger suite
+
+// Metazooa
+type animalParser struct{ pattern }
+
+func (animalParser) Name() string { return "Animal" }
+
+// Score is the number of guesses. Giving up shows 20 tiles.
+func (p animalParser) Extract(content string) (*Score, error) {
+	score, _, err := p.extract(content)
+	if err != nil {
+		return nil, err
+	}
+	score.Score = strconv.Itoa(strings.Count(content, "🟧") + strings.Count(content, "🟩") + strings.Count(content, "🟥"))
+	return score, nil
+}
+
+func (animalParser) Normalize(score *Score) error {
+	if score.Score == "20" {
+		score.Win = "N"
+	} else {
+		score.Win = "Y"
+	}
+	return nil
+}
+
+func init() {
+	registerGameParser(animalParser{newPattern(`(?s)(?P<game>Animal) #(?P<game_no>\d+).*`)})
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestAnimalParser(t *testing.T) {
+	parser := getGameParser("Animal")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "🦡 Animal #770 🐮\nI figured it out in 9 guesses!\n🟧🟧🟧🟧🟩🟩🟩🟩🟩\n🔥 1 | Avg. Guesses: 6.9\n\nhttps://metazooa.com/\n#metazooa",
+			output: Score{Game: "Animal", GameNumber: "770", Score: "9", Win: "Y"},
+		},
+		{
+			input:  "🐹 Animal #772 🐃\nI was stumped by today's game!\n🟥🟥🟥🟥🟥🟥🟥🟥🟥🟥🟥🟧🟥🟥🟧🟧🟧🟥🟥🟥\n🔥 0 | Avg. Guesses: 0",
+			output: Score{Game: "Animal", GameNumber: "772", Score: "20", Win: "N"},
+		},
+	})
+	checkGameParserRejects(t, parser, []string{
+		"Animal Crossing",
+	})
+}
+
+// Animal shares start at the game name, so one posted after another share is split from it
+func TestAnimalAfterAnotherShare(t *testing.T) {
+	scores, err := ParseScoreFromContent("Wordle 1,327 4/6\n\n🦡 Animal #770 🐮\nI figured it out in 9 guesses!\n🟧🟧🟧🟧🟩🟩🟩🟩🟩")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scores) != 2 || scores[0].Game != "Wordle" || scores[1].Game != "Animal" || scores[1].Score != "9" {
+		t.Errorf("expected Wordle and Animal scores, got %+v", scores)
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// New York Times games
+
+type wordleParser struct{ pattern }
+
+func (wordleParser) Name() string { return "Wordle" }
+
+func (p wordleParser) Extract(content string) (*Score, error) {
+	score, _, err := p.extract(content)
+	if err != nil {
+		return nil, err
+	}
+	score.Guesses = parseGrid(content, 1)
+	return score, nil
+}
+
+func (wordleParser) Normalize(score *Score) error {
+	normalizeFailPenalty(score, "7")
+	return nil
+}
+
+type connectionsParser struct{ pattern }
+
+func (connectionsParser) Name() string { return "Connections" }
+
+var connectionsRows = regexp.MustCompile("(?s)[🟨🟩🟪🟦]+")
+
+// Category colors from easiest to hardest
+var connectionsColors = map[string]string{
+	"🟨🟨🟨🟨": "Y",
+	"🟩🟩🟩🟩": "G",
+	"🟦🟦🟦🟦": "B",
+	"🟪🟪🟪🟪": "P",
+}
+
+// Score is the number of rows guessed. Losses count as 7.
+//
+// Details:
+//   - mistakes: rows that weren't a category (4 on a loss)
+//   - order: solved categories in order, Y (yellow), G (green), B (blue) and P (purple)
+//   - purple_first: Y if the hardest category was solved first
+//   - reverse_rainbow: Y if every category was solved from hardest to easiest
+func (p connectionsParser) Extract(content string) (*Score, error) {
+	score, _, err := p.extract(content)
+	if err != nil {
+		return nil, err
+	}
+	lines := connectionsRows.FindAllString(content, 64)
+	order := ""
+	mistakes := 0
+	for _, line := range lines {
+		color, ok := connectionsColors[line]
+		if ok {
+			order += color
+		} else {
+			mistakes++
+		}
+	}
+	if len(order) == 4 {
+		score.Score = strconv.Itoa(len(lines))
+	} else {
+		score.Score = "X"
+	}
+	score.Details = map[string]string{
+		"mistakes":        strconv.Itoa(mistakes),
+		"order":           order,
+		"purple_first":    yesNo(strings.HasPrefix(order, "P")),
+		"reverse_rainbow": yesNo(order == "PBGY"),
+	}
+	return score, nil
+}
+
+func (connectionsParser) Normalize(score *Score) error {
+	normalizeFailPenalty(score, "7")
+	return nil
+}
+
+type strandsParser struct{ pattern }
+
+func (strandsParser) Name() string { return "Strands" }
+
+// Score is the number of hints used
+func (p strandsParser) Extract(content string) (*Score, error) {
+	score, _, err := p.extract(content)
+	if err != nil {
+		return nil, err
+	}
+	score.Score = strconv.Itoa(strings.Count(content, "💡"))
+	return score, nil
+}
+
+func (strandsParser) Normalize(score *Score) error {
+	score.Win = "Y"
+	return nil
+}
+
+// Date for games without puzzle numbers, as YYYYMMDD
+func dateGameNumber(year string, month string, day string) string {
+	m, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+	return fmt.Sprintf("%s%02d%02d", year, m, d)
+}
+
+type miniCrosswordParser struct{ pattern }
+
+func (miniCrosswordParser) Name() string { return "Mini Crossword" }
+
+// Score is the solve time in seconds. The Mini has no puzzle number, so the date is used instead.
+func (p miniCrosswordParser) Extract(content string) (*Score, error) {
+	score, captures, err := p.extract(content)
+	if err != nil {
+		return nil, err
+	}
+	score.GameNumber = dateGameNumber(captures["year"], captures["month"], captures["day"])
+	score.Score, err = parseSolveTime(captures["time"])
+	if err != nil {
+		return nil, err
+	}
+	return score, nil
+}
+
+func (miniCrosswordParser) Normalize(score *Score) error {
+	score.Win = "Y"
+	return nil
+}
+
+type spellingBeeParser struct{ pattern }
+
+func (spellingBeeParser) Name() string { return "Spelling Bee" }
+
+// Score is points. Puzzles without a number are dated when the message is parsed.
+//
+// Details:
+//   - rank: Beginner through Queen Bee
+func (p spellingBeeParser) Extract(content string) (*Score, error) {
+	score, captures, err := p.extract(content)
+	if err != nil {
+		return nil, err
+	}
+	score.Details = map[string]string{"rank": captures["rank"]}
+	return score, nil
+}
+
+func (spellingBeeParser) Normalize(score *Score) error {
+	score.Win = "Y"
+	return nil
+}
+
+type letterBoxedParser struct{ pattern }
+
+func (letterBoxedParser) Name() string { return "Letter Boxed" }
+
+// Score is the number of words used
+func (p letterBoxedParser) Extract(content string) (*Score, error) {
+	score, _, err := p.extract(content)
+	return score, err
+}
+
+func (letterBoxedParser) Normalize(score *Score) error {
+	score.Win = "Y"
+	return nil
+}
+
+type pipsParser struct{ pattern }
+
+func (pipsParser) Name() string { return "Pips" }
+
+// Each difficulty is its own puzzle, so it's tracked as its own game (Pips Easy, Pips Medium, Pips Hard).
+// Score is the solve time in seconds.
+//
+// Details:
+//   - difficulty: Easy, Medium or Hard
+func (p pipsParser) Extract(content string) (*Score, error) {
+	score, captures, err := p.extract(content)
+	if err != nil {
+		return nil, err
+	}
+	score.Game = captures["game"] + " " + captures["difficulty"]
+	score.Score, err = parseSolveTime(captures["time"])
+	if err != nil {
+		return nil, err
+	}
+	score.Details = map[string]string{"difficulty": captures["difficulty"]}
+	return score, nil
+}
+
+func (pipsParser) Normalize(score *Score) error {
+	score.Win = "Y"
+	return nil
+}
+
+func init() {
+	registerGameParser(wordleParser{newPattern(`(?s)(?P<game>Wordle) (?P<game_no>[\d,]+) (?P<score>\w)\/6(?P<hardmode>[*]?)`)})
+	registerGameParser(connectionsParser{newPattern(`(?s)(?P<game>Connections).*Puzzle #(?P<game_no>\d+)`)})
+	registerGameParser(strandsParser{newPattern(`(?s)(?P<game>Strands) #(?P<game_no>\d+).*`)})
+	registerGameParser(miniCrosswordParser{newPattern(`(?s)I solved the (?:[A-Za-z]+ )?(?P<month>\d{1,2})/(?P<day>\d{1,2})/(?P<year>\d{4}) New York Times (?P<game>Mini Crossword) in (?P<time>(?:\d+:)?\d+:\d{2})`)})
+	registerGameParser(spellingBeeParser{newPattern(`(?s)(?P<game>Spelling Bee)(?: #(?P<game_no>\d+))?.*?(?P<rank>Queen Bee|Genius|Amazing|Great|Nice|Solid|Good Start|Good|Moving Up|Beginner).*?(?P<score>\d+) points?`)})
+	registerGameParser(letterBoxedParser{newPattern(`(?s)(?P<game>Letter Boxed)(?: #(?P<game_no>\d+))?.*?(?P<score>\d+) words?`)})
+	registerGameParser(pipsParser{newPattern(`(?s)(?P<game>Pips) #(?P<game_no>\d+) (?P<difficulty>Easy|Medium|Hard).*?(?P<time>(?:\d+:)?\d+:\d{2})`)})
+	registerGameInfo("Wordle", GameInfo{Unit: "guesses", Epoch: "2021-06-19"})
+	registerGameInfo("Connections", GameInfo{Unit: "guesses", Epoch: "2023-06-11"})
+	registerGameInfo("Strands", GameInfo{Unit: "hints", Epoch: "2024-03-03"})
+	registerGameInfo("Mini Crossword", GameInfo{Unit: "seconds"})
+	registerGameInfo("Spelling Bee", GameInfo{Unit: "points", HigherIsBetter: true, Epoch: "2018-05-08"})
+	registerGameInfo("Letter Boxed", GameInfo{Unit: "words"})
+	registerGameInfo("Pips", GameInfo{Unit: "seconds"})
+	// Each difficulty is saved as its own game, all sharing the puzzle number
+	for _, difficulty := range []string{"Easy", "Medium", "Hard"} {
+		registerGameInfo("Pips "+difficulty, GameInfo{Unit: "seconds", Epoch: "2025-08-17"})
+	}
+}
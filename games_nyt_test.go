@@ -0,0 +1,147 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestWordleParser(t *testing.T) {
+	parser := getGameParser("Wordle")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Wordle 771 3/6*\r\n\r\n⬛⬛⬛⬛🟩\r\n🟨🟩⬛⬛🟩\r\n🟩🟩🟩🟩🟩",
+			output: Score{Game: "Wordle", GameNumber: "771", Score: "3", Hardmode: "*", Win: "Y"},
+		},
+		{
+			input:  "Wordle 1,327 X/6\n⬜🟩🟨⬜⬜\n⬜⬜🟨⬜⬜\n⬜⬜⬜⬜🟨\n🟩🟩🟩⬜🟩\n🟩🟩🟩⬜🟩\n🟩🟩🟩⬜🟩",
+			output: Score{Game: "Wordle", GameNumber: "1,327", Score: "7", Win: "N"},
+		},
+	})
+	checkGameParserRejects(t, parser, []string{
+		"Daily Dordle 0597 4&6/7",
+		"Wordle is fun",
+	})
+}
+
+func TestConnectionsParser(t *testing.T) {
+	parser := getGameParser("Connections")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Connections\nPuzzle #100\n🟪🟪🟪🟪\n🟦🟦🟦🟦\n🟩🟩🟩🟩\n🟨🟨🟨🟨",
+			output: Score{Game: "Connections", GameNumber: "100", Score: "4", Win: "Y"},
+		},
+		{
+			input:  "Connections\nPuzzle #101\n🟨🟨🟨🟩\n🟨🟨🟨🟨\n🟦🟦🟪🟦\n🟦🟦🟪🟦\n🟦🟦🟪🟦",
+			output: Score{Game: "Connections", GameNumber: "101", Score: "7", Win: "N"},
+		},
+	})
+	checkGameParserRejects(t, parser, []string{
+		"Connections are important",
+	})
+}
+
+func TestStrandsParser(t *testing.T) {
+	parser := getGameParser("Strands")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Strands #450\n“Spangram only”\n🔵🔵🔵🟡\n🔵🔵",
+			output: Score{Game: "Strands", GameNumber: "450", Score: "0", Win: "Y"},
+		},
+	})
+	checkGameParserRejects(t, parser, []string{
+		"Strands of hair",
+	})
+}
+
+func TestConnectionsDetails(t *testing.T) {
+	parser := getGameParser("Connections")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input: "Connections\nPuzzle #100\n🟪🟪🟪🟪\n🟦🟦🟦🟦\n🟩🟩🟩🟩\n🟨🟨🟨🟨",
+			output: Score{Game: "Connections", GameNumber: "100", Score: "4", Win: "Y", Details: map[string]string{
+				"mistakes": "0", "order": "PBGY", "purple_first": "Y", "reverse_rainbow": "Y",
+			}},
+		},
+		{
+			input: "Connections Puzzle #608 🟪🟨🟦🟦 🟪🟪🟪🟪 🟨🟦🟦🟦 🟩🟩🟩🟩 🟨🟨🟨🟨 🟦🟦🟦🟦",
+			output: Score{Game: "Connections", GameNumber: "608", Score: "6", Win: "Y", Details: map[string]string{
+				"mistakes": "2", "order": "PGYB", "purple_first": "Y", "reverse_rainbow": "N",
+			}},
+		},
+		{
+			input: "Connections Puzzle #613 🟨🟩🟨🟨 🟨🟨🟨🟨 🟦🟪🟦🟦 🟦🟪🟪🟦 🟩🟩🟩🟩 🟦🟪🟪🟦",
+			output: Score{Game: "Connections", GameNumber: "613", Score: "7", Win: "N", Details: map[string]string{
+				"mistakes": "4", "order": "YG", "purple_first": "N", "reverse_rainbow": "N",
+			}},
+		},
+	})
+}
+
+func TestMiniCrosswordParser(t *testing.T) {
+	parser := getGameParser("Mini Crossword")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "I solved the 9/14/2024 New York Times Mini Crossword in 0:31!\nhttps://www.nytimes.com/badges/games/mini.html?d=2024-09-14&t=31",
+			output: Score{Game: "Mini Crossword", GameNumber: "20240914", Score: "31", Win: "Y"},
+		},
+		{
+			input:  "I solved the Saturday 10/18/2026 New York Times Mini Crossword in 1:02:05!",
+			output: Score{Game: "Mini Crossword", GameNumber: "20261018", Score: "3725", Win: "Y"},
+		},
+	})
+	checkGameParserRejects(t, parser, []string{
+		"Mini Sudoku #28 | 0:54 and flawless ✏️",
+	})
+}
+
+func TestSpellingBeeParser(t *testing.T) {
+	parser := getGameParser("Spelling Bee")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Spelling Bee #1234 🐝\nRank: Genius\n183 points",
+			output: Score{Game: "Spelling Bee", GameNumber: "1234", Score: "183", Win: "Y", Details: map[string]string{"rank": "Genius"}},
+		},
+		{
+			input:  "Spelling Bee\nGood Start with 12 points",
+			output: Score{Game: "Spelling Bee", Score: "12", Win: "Y", Details: map[string]string{"rank": "Good Start"}},
+		},
+	})
+	checkGameParserRejects(t, parser, []string{
+		"Spelling Bee is hard today",
+	})
+}
+
+func TestLetterBoxedParser(t *testing.T) {
+	parser := getGameParser("Letter Boxed")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Letter Boxed #1178\nSolved in 2 words! 🟥🟦",
+			output: Score{Game: "Letter Boxed", GameNumber: "1178", Score: "2", Win: "Y"},
+		},
+	})
+}
+
+func TestPipsParser(t *testing.T) {
+	parser := getGameParser("Pips")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Pips #46 Easy 🟢\n1:03",
+			output: Score{Game: "Pips Easy", GameNumber: "46", Score: "63", Win: "Y", Details: map[string]string{"difficulty": "Easy"}},
+		},
+		{
+			input:  "Pips #46 Hard 🔴\n12:40",
+			output: Score{Game: "Pips Hard", GameNumber: "46", Score: "760", Win: "Y", Details: map[string]string{"difficulty": "Hard"}},
+		},
+	})
+}
+
+func TestNYTGameInfo(t *testing.T) {
+	if info := getGameInfo("Spelling Bee"); !info.HigherIsBetter || info.Unit != "points" {
+		t.Fatalf("Spelling Bee info is %+v", info)
+	}
+	if info := getGameInfo("Pips Medium"); info.HigherIsBetter || info.Unit != "seconds" {
+		t.Fatalf("Pips Medium info is %+v", info)
+	}
+	if info := getGameInfo("Wordle"); info.HigherIsBetter || info.Unit != "guesses" {
+		t.Fatalf("Wordle info is %+v", info)
+	}
+}
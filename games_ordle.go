@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Multi-board Wordle variants
+
+// Board results in share text: keycap digits, 🔟, clock faces for 10-21, 🟥 for a failed board,
+// or plain numbers for games with too many guesses for emoji. "35/37" totals and "10:22.12" times are
+// skipped.
+var boardResult = regexp.MustCompile(`\d+(?:[/:.]\d+)+|[0-9]\x{FE0F}?\x{20E3}|🔟|[🕐-🕛]|🟥|\bX\b|\d+`)
+
+// Lines of a share that hold stats rather than the grid of board results
+var statsLine = regexp.MustCompile(`(?i)^\s*(time|guesses)\s*:`)
+
+// Value of a clock face. 🕙, 🕚 and 🕛 are 10 to 12, 🕐 through 🕘 continue on from 13 to 21.
+func clockValue(face rune) int {
+	hour := int(face-'🕐') + 1
+	if hour < 10 {
+		return hour + 12
+	}
+	return hour
+}
+
+// Read board results in order. Failed boards are returned as 0.
+func parseBoardResults(content string, boards int) ([]int, error) {
+	var grid []string
+	for _, line := range strings.Split(content, "\n") {
+		if !statsLine.MatchString(line) {
+			grid = append(grid, line)
+		}
+	}
+	var results []int
+	for _, token := range boardResult.FindAllString(strings.Join(grid, "\n"), -1) {
+		if len(results) == boards {
+			break
+		}
+		switch {
+		case strings.ContainsAny(token, "/:."):
+			continue
+		case token == "🟥" || token == "X":
+			results = append(results, 0)
+		case token == "🔟":
+			results = append(results, 10)
+		case strings.HasSuffix(token, "⃣"):
+			results = append(results, int(token[0]-'0'))
+		default:
+			face := []rune(token)[0]
+			if face >= '🕐' && face <= '🕛' {
+				results = append(results, clockValue(face))
+			} else {
+				value, _ := strconv.Atoi(token)
+				results = append(results, value)
+			}
+		}
+	}
+	if len(results) < boards {
+		return nil, fmt.Errorf("found %d of %d boards: %s", len(results), boards, content)
+	}
+	return results, nil
+}
+
+// Dordle, Quordle, Octordle, Sedecordle and Duotrigordle
+//
+// Score is the total guesses across every board. Failed boards count as the penalty.
+// Games that print their own score (Octordle) use that instead.
+//
+// Details:
+//   - boards: guesses for each board, X for a failure
+type multiboardParser struct {
+	pattern
+	name    string
+	boards  int
+	penalty int
+}
+
+func (p multiboardParser) Name() string { return p.name }
+
+func (p multiboardParser) Extract(content string) (*Score, error) {
+	score, captures, err := p.extract(content)
+	if err != nil {
+		return nil, err
+	}
+	// Results are either captured (Dordle's "4&6") or follow the game number
+	results := captures["results"]
+	if results == "" {
+		loc := p.re.FindStringSubmatchIndex(content)
+		i := p.re.SubexpIndex("game_no")
+		results = content[loc[2*i+1]:]
+	}
+	values, err := parseBoardResults(results, p.boards)
+	if err != nil {
+		return nil, err
+	}
+	total := 0
+	win := "Y"
+	boards := make([]string, len(values))
+	for i, value := range values {
+		if value == 0 {
+			total += p.penalty
+			win = "N"
+			boards[i] = "X"
+		} else {
+			total += value
+			boards[i] = strconv.Itoa(value)
+		}
+	}
+	if score.Score == "" {
+		score.Score = strconv.Itoa(total)
+	}
+	score.Win = win
+	score.Details = map[string]string{"boards": strings.Join(boards, ",")}
+	if p.boards == 2 {
+		score.Guesses = parseGrid(content, 2)
+	}
+	return score, nil
+}
+
+func (multiboardParser) Normalize(score *Score) error {
+	return nil
+}
+
+func init() {
+	// Dordle has always counted a failed board as 7
+	registerGameParser(multiboardParser{newPattern(`(?s)(?P<game>[A-Za-z ]*Dordle) (?P<game_no>\d+) (?P<results>\w[&]\w)\/7`), "Dordle", 2, 7})
+	registerGameParser(multiboardParser{newPattern(`(?s)(?P<game>[A-Za-z ]*Quordle) #?(?P<game_no>\d+)`), "Quordle", 4, 10})
+	registerGameParser(multiboardParser{newPattern(`(?s)(?P<game>[A-Za-z ]*Octordle) #(?P<game_no>\d+).*Score[:] (?P<score>\d+)`), "Octordle", 8, 14})
+	registerGameParser(multiboardParser{newPattern(`(?s)(?P<game>[A-Za-z ]*Sedecordle) #?(?P<game_no>\d+)`), "Sedecordle", 16, 22})
+	registerGameParser(multiboardParser{newPattern(`(?s)(?P<game>[A-Za-z ]*Duotrigordle) #?(?P<game_no>\d+)`), "Duotrigordle", 32, 38})
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestDordleParser(t *testing.T) {
+	parser := getGameParser("Dordle")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Daily Dordle 0600 3&5/7\n⬜🟨⬜⬜⬜ 🟨⬜⬜⬜⬜\n🟩🟩🟩🟩🟩 ⬜🟨🟨⬜⬜",
+			output: Score{Game: "Daily Dordle", GameNumber: "0600", Score: "8", Win: "Y"},
+		},
+		{
+			input:  "Free Dordle 12 X&4/7",
+			output: Score{Game: "Free Dordle", GameNumber: "12", Score: "11", Win: "N"},
+		},
+	})
+	checkGameParserRejects(t, parser, []string{
+		"Wordle 771 3/6*",
+		"Daily Octordle #553",
+	})
+}
+
+func TestOctordleParser(t *testing.T) {
+	parser := getGameParser("Octordle")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Daily Octordle #553\r\n🔟7️⃣\r\n6️⃣8️⃣\r\n3️⃣5️⃣\r\n9️⃣🕚\r\nScore: 59",
+			output: Score{Game: "Daily Octordle", GameNumber: "553", Score: "59", Win: "Y"},
+		},
+		{
+			input:  "Daily Octordle #501\r\n6️⃣🟥\r\n5️⃣8️⃣\r\n3️⃣🟥\r\n🕐🔟\r\nScore: 73",
+			output: Score{Game: "Daily Octordle", GameNumber: "501", Score: "73", Win: "N"},
+		},
+	})
+	checkGameParserRejects(t, parser, []string{
+		"Daily Octordle #553 without a score",
+	})
+}
+
+func TestOctordleBoards(t *testing.T) {
+	parser := getGameParser("Octordle")
+	checkGameParser(t, parser, []parserCase{
+		{ // User text in middle of string (courtesy: Josh)
+			input:  "Daily Octordle #1131 7️⃣🔟 5️⃣8️⃣ 🟥🟥 oof 🟥🕐 Score: 85",
+			output: Score{Game: "Daily Octordle", GameNumber: "1131", Score: "85", Win: "N", Details: map[string]string{"boards": "7,10,5,8,X,X,X,13"}},
+		},
+		{
+			input:  "Daily Sequence Octordle #563 4️⃣5️⃣ 7️⃣8️⃣ 9️⃣🔟 🕚🕛 Score: 66",
+			output: Score{Game: "Daily Sequence Octordle", GameNumber: "563", Score: "66", Win: "Y", Details: map[string]string{"boards": "4,5,7,8,9,10,11,12"}},
+		},
+	})
+}
+
+func TestQuordleParser(t *testing.T) {
+	parser := getGameParser("Quordle")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Daily Quordle 1000\n6️⃣4️⃣\n8️⃣5️⃣\nm-w.com/games/quordle/",
+			output: Score{Game: "Daily Quordle", GameNumber: "1000", Score: "23", Win: "Y", Details: map[string]string{"boards": "6,4,8,5"}},
+		},
+		{
+			input:  "Daily Quordle #1001\n7️⃣🟥\n9️⃣3️⃣\nm-w.com/games/quordle/",
+			output: Score{Game: "Daily Quordle", GameNumber: "1001", Score: "29", Win: "N", Details: map[string]string{"boards": "7,X,9,3"}},
+		},
+	})
+	checkGameParserRejects(t, parser, []string{
+		"Daily Octordle #553",
+	})
+	_, err := parseGame(parser, "Daily Quordle 1002\n6️⃣4️⃣")
+	if err == nil {
+		t.Fatalf("Quordle with missing boards should not parse")
+	}
+}
+
+func TestSedecordleParser(t *testing.T) {
+	parser := getGameParser("Sedecordle")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Daily Sedecordle #0736\n\n5️⃣7️⃣8️⃣🔟\n🕚🕛🕐🕑\n🕒🕓🕔🕕\n🕖🕗🕘9️⃣\nsedecordle.com",
+			output: Score{Game: "Daily Sedecordle", GameNumber: "0736", Score: "215", Win: "Y", Details: map[string]string{"boards": "5,7,8,10,11,12,13,14,15,16,17,18,19,20,21,9"}},
+		},
+		{
+			input:  "Daily Sedecordle #0737\n\n5️⃣7️⃣8️⃣🔟\n🕚🕛🕐🕑\n🕒🕓🕔🕕\n🕖🕗🟥🟥\nsedecordle.com",
+			output: Score{Game: "Daily Sedecordle", GameNumber: "0737", Score: "229", Win: "N", Details: map[string]string{"boards": "5,7,8,10,11,12,13,14,15,16,17,18,19,20,X,X"}},
+		},
+	})
+}
+
+func TestDuotrigordleParser(t *testing.T) {
+	parser := getGameParser("Duotrigordle")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input: "Daily Duotrigordle #0423\nGuesses: 36/37\n" +
+				"05 06 07 08 09 10 11 12\n13 14 15 16 17 18 19 20\n21 22 23 24 25 26 27 28\n29 30 31 32 33 34 35 36\nhttps://duotrigordle.com/",
+			output: Score{Game: "Daily Duotrigordle", GameNumber: "0423", Score: "656", Win: "Y"},
+		},
+		{
+			input: "Daily Duotrigordle #0424\nGuesses: 37/37\n" +
+				"05 06 07 08 09 10 11 12\n13 14 15 16 17 18 19 20\n21 22 23 24 25 26 27 28\n29 30 31 32 33 34 🟥 🟥\nhttps://duotrigordle.com/",
+			output: Score{Game: "Daily Duotrigordle", GameNumber: "0424", Score: "661", Win: "N"},
+		},
+		{ // Shares from duotrigordle.com list the time taken before the boards
+			input: "Daily Duotrigordle #1130\nGuesses: 37/37\nTime: 10:22.12\n" +
+				"07 08 09 10 11 12 13 14\n15 16 17 18 19 20 21 22\n23 24 25 26 27 28 29 30\n31 32 33 34 35 36 37 🟥\nhttps://duotrigordle.com/",
+			output: Score{Game: "Daily Duotrigordle", GameNumber: "1130", Score: "720", Win: "N", Details: map[string]string{"boards": "7,8,9,10,11,12,13,14,15,16,17,18,19,20,21,22,23,24,25,26,27,28,29,30,31,32,33,34,35,36,37,X"}},
+		},
+	})
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+)
+
+// Math and word-shape puzzles
+
+// Nerdle and Costcodle use the "x/6" format
+type sixGuessParser struct {
+	pattern
+	name string
+}
+
+func (p sixGuessParser) Name() string { return p.name }
+
+func (p sixGuessParser) Extract(content string) (*Score, error) {
+	score, _, err := p.extract(content)
+	if err != nil {
+		return nil, err
+	}
+	score.Game = p.name
+	score.Score = strings.ToUpper(score.Score)
+	return score, nil
+}
+
+func (sixGuessParser) Normalize(score *Score) error {
+	normalizeFailPenalty(score, "7")
+	return nil
+}
+
+type waffleParser struct{ pattern }
+
+func (waffleParser) Name() string { return "Waffle" }
+
+// Score is stars out of 5, one for each swap left over. Higher is better, a loss is 0.
+func (p waffleParser) Extract(content string) (*Score, error) {
+	score, captures, err := p.extract(content)
+	if err != nil {
+		return nil, err
+	}
+	score.Game = "Waffle"
+	if captures["deluxe"] != "" {
+		score.Game = "Deluxe Waffle"
+	}
+	score.Score = strings.ToUpper(score.Score)
+	return score, nil
+}
+
+func (waffleParser) Normalize(score *Score) error {
+	normalizeFailPenalty(score, "0")
+	return nil
+}
+
+type squaredleParser struct{ pattern }
+
+func (squaredleParser) Name() string { return "Squaredle" }
+
+// Score is the number of required words found. Finding all of them is a win.
+//
+// Details:
+//   - words: required words in the puzzle
+//   - hints: hints used
+func (p squaredleParser) Extract(content string) (*Score, error) {
+	score, captures, err := p.extract(content)
+	if err != nil {
+		return nil, err
+	}
+	hints := captures["hints"]
+	if hints == "" || hints == "no" {
+		hints = "0"
+	}
+	score.Score = captures["found"]
+	score.Win = yesNo(captures["found"] == captures["words"])
+	score.Details = map[string]string{"words": captures["words"], "hints": hints}
+	return score, nil
+}
+
+func (squaredleParser) Normalize(score *Score) error {
+	return nil
+}
+
+func init() {
+	registerGameParser(sixGuessParser{newPattern(`(?s)(?P<game>nerdlegame) (?P<game_no>\d+) (?P<score>[1-6Xx])/6`), "Nerdle"})
+	registerGameParser(sixGuessParser{newPattern(`(?s)(?P<game>Costcodle) #(?P<game_no>\d+) (?P<score>[1-6Xx])/6`), "Costcodle"})
+	registerGameParser(waffleParser{newPattern(`(?s)#(?P<deluxe>deluxe)?(?P<game>waffle)(?P<game_no>\d+) (?P<score>[0-5Xx])/5`)})
+	registerGameParser(squaredleParser{newPattern(`(?s)(?P<game>Squaredle) #(?P<game_no>\d+)(?: with (?P<hints>\d+|no) hints?)?.*?Words: (?P<found>\d+)/(?P<words>\d+)`)})
+	registerGameInfo("Nerdle", GameInfo{Unit: "guesses", Epoch: "2022-01-19"})
+	registerGameInfo("Waffle", GameInfo{Unit: "stars", HigherIsBetter: true, Epoch: "2022-01-28"})
+	registerGameInfo("Squaredle", GameInfo{Unit: "words", HigherIsBetter: true})
+	registerGameInfo("Costcodle", GameInfo{Unit: "guesses"})
+}
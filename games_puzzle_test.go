@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestNerdleParser(t *testing.T) {
+	parser := getGameParser("Nerdle")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "nerdlegame 728 3/6\n\n⬛️🟪⬛️🟪🟩⬛️🟪⬛️\n🟪🟩🟪🟩🟩⬛️🟪🟪\n🟩🟩🟩🟩🟩🟩🟩🟩\n\n#nerdle",
+			output: Score{Game: "Nerdle", GameNumber: "728", Score: "3", Win: "Y"},
+		},
+		{
+			input:  "nerdlegame 729 X/6\n\n⬛️🟪⬛️🟪🟩⬛️🟪⬛️\n\n#nerdle",
+			output: Score{Game: "Nerdle", GameNumber: "729", Score: "7", Win: "N"},
+		},
+	})
+}
+
+func TestWaffleParser(t *testing.T) {
+	parser := getGameParser("Waffle")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "#waffle807 4/5\n\n🟩🟩🟩🟩🟩\n🟩⭐️🟩⭐️🟩\n🟩🟩⭐️🟩🟩\n🟩⭐️🟩🟩🟩\n🟩🟩🟩🟩🟩\n\n🔥 streak: 12\nwafflegame.net",
+			output: Score{Game: "Waffle", GameNumber: "807", Score: "4", Win: "Y"},
+		},
+		{
+			input:  "#waffle808 X/5\n\n🟩🟩🟩🟩🟩\n🟩⬛️🟩⬛️🟩\n🟩🟩🟩🟩🟩\n🟩⬛️🟩⬛️🟩\n🟩🟩🟩🟩🟩\nwafflegame.net",
+			output: Score{Game: "Waffle", GameNumber: "808", Score: "0", Win: "N"},
+		},
+		{
+			input:  "#deluxewaffle120 5/5\n\n⭐️⭐️⭐️⭐️⭐️\nwafflegame.net",
+			output: Score{Game: "Deluxe Waffle", GameNumber: "120", Score: "5", Win: "Y"},
+		},
+	})
+	if !getGameInfo("Waffle").HigherIsBetter {
+		t.Fatalf("Waffle should rank higher scores first")
+	}
+}
+
+func TestSquaredleParser(t *testing.T) {
+	parser := getGameParser("Squaredle")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "I solved Squaredle #1234 with 2 hints 🔥\nWords: 45/45\nBonus words: 3/12\nsquaredle.net",
+			output: Score{Game: "Squaredle", GameNumber: "1234", Score: "45", Win: "Y", Details: map[string]string{"words": "45", "hints": "2"}},
+		},
+		{
+			input:  "I played Squaredle #1235\nWords: 30/41\nsquaredle.net",
+			output: Score{Game: "Squaredle", GameNumber: "1235", Score: "30", Win: "N", Details: map[string]string{"words": "41", "hints": "0"}},
+		},
+	})
+}
+
+func TestCostcodleParser(t *testing.T) {
+	parser := getGameParser("Costcodle")
+	checkGameParser(t, parser, []parserCase{
+		{
+			input:  "Costcodle #123 3/6\n⬆️🟥\n⬇️🟨\n✅\nhttps://costcodle.com",
+			output: Score{Game: "Costcodle", GameNumber: "123", Score: "3", Win: "Y"},
+		},
+		{
+			input:  "Costcodle #124 X/6\n⬆️🟥\n⬇️🟥\n⬆️🟨\n⬇️🟨\n⬆️🟨\n⬇️🟨\nhttps://costcodle.com",
+			output: Score{Game: "Costcodle", GameNumber: "124", Score: "7", Win: "N"},
+		},
+	})
+}
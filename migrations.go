@@ -0,0 +1,526 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Schema changes, applied in order. Each runs in its own transaction and is recorded in schema_version.
+// Released migrations must not change. Add a new one instead.
+//
+// Databases from before versioning may be part way through the first migrations, so those are written to
+// be safe to run again.
+var migrations = []migration{
+	{1, "create tables", createTables},
+	{2, "key scores by message and game", migrateScoreIDs},
+	{3, "keep message content", func(tx dbtx) error {
+		return addMissingColumn(tx, "scores", "content", "TEXT")
+	}},
+	{4, "integer game numbers and scores", migrateNumericScores},
+	{5, "players by discord user id", migratePlayers},
+	{6, "merged players", func(tx dbtx) error {
+		// Each alias (Discord user ID, or username for older scores) counts as the player it was merged into
+		_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS player_aliases (
+			alias_id TEXT UNIQUE,
+			player_id TEXT,
+			merged TEXT
+		)`)
+		return err
+	}},
+	{7, "puzzle dates from game epochs", migratePuzzleDates},
+	{8, "score audit log", createScoreAudit},
+	{9, "duplicate policy", func(tx dbtx) error {
+		// Per guild settings, such as duplicate_policy
+		_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS guild_settings (
+			guild_id TEXT,
+			name TEXT,
+			value TEXT,
+			UNIQUE (guild_id, name)
+		)`)
+		if err != nil {
+			return err
+		}
+		// Why a message is waiting for review: it didn't parse (NULL) or is a repost that doesn't count
+		return addMissingColumn(tx, "unparsed", "reason", "TEXT")
+	}},
+}
+
+type migration struct {
+	Version int
+	Name    string
+	up      func(tx dbtx) error
+}
+
+// Shared by *sql.DB and *sql.Tx so schema helpers work inside migrations
+type dbtx interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// Newest schema this build knows about
+func latestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// Version of the database schema. 0 for a new or unversioned database.
+func schemaVersion(db dbtx) (int, error) {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER UNIQUE,
+			name TEXT,
+			applied TEXT
+		)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create schema_version: %v", err)
+	}
+	var version int
+	err = db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get schema version: %v", err)
+	}
+	return version, nil
+}
+
+// Refuse to touch a database written by a newer build
+func checkSchemaVersion(db dbtx) (int, error) {
+	version, err := schemaVersion(db)
+	if err != nil {
+		return 0, err
+	}
+	if version > latestSchemaVersion() {
+		return version, fmt.Errorf("database schema version %d is newer than this build supports (%d), upgrade %s", version, latestSchemaVersion(), appExecName())
+	}
+	return version, nil
+}
+
+// Migrations not yet applied to the database
+func pendingMigrations(db dbtx) ([]migration, error) {
+	version, err := checkSchemaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	var pending []migration
+	for _, m := range migrations {
+		if m.Version > version {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Apply pending migrations in order
+func migrateDatabase(db *database) error {
+	pending, err := pendingMigrations(db)
+	if err != nil {
+		return err
+	}
+	// New databases are created quietly
+	upgrade := len(pending) < len(migrations)
+	for _, m := range pending {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		err = m.up(tx)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed migration %d (%s): %v", m.Version, m.Name, err)
+		}
+		_, err = tx.Exec(
+			"INSERT INTO schema_version (version, name, applied) VALUES (?, ?, ?)",
+			m.Version, m.Name, time.Now().UTC().Format(time.RFC3339),
+		)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %v", m.Version, err)
+		}
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+		if upgrade {
+			logPrintln("Applied migration %d: %s", m.Version, m.Name)
+		}
+	}
+	return nil
+}
+
+// Print applied and pending migrations. Only reads, so a database without schema_version is left as it is
+// and reported as version 0.
+func printMigrationStatus(db *database, out io.Writer) error {
+	exists, err := hasTable(db, "schema_version")
+	if err != nil {
+		return err
+	}
+	version := 0
+	applied := map[int]string{}
+	if exists {
+		rows, err := db.Query("SELECT version, applied FROM schema_version")
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var v int
+			var date string
+			err := rows.Scan(&v, &date)
+			if err != nil {
+				return err
+			}
+			applied[v] = date
+			version = max(version, v)
+		}
+		if err = rows.Err(); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(out, "Schema version %d, this build supports %d\n", version, latestSchemaVersion())
+	for _, m := range migrations {
+		status := "pending"
+		if date, ok := applied[m.Version]; ok {
+			status = "applied " + date
+		}
+		fmt.Fprintf(out, "%4d  %-35s %s\n", m.Version, m.Name, status)
+	}
+	if version > latestSchemaVersion() {
+		fmt.Fprintln(out, "Database is newer than this build")
+	}
+	return nil
+}
+
+// Check if a table exists without creating it
+func hasTable(db *database, table string) (bool, error) {
+	query := "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+	if db.postgres {
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?"
+	}
+	var count int
+	err := db.QueryRow(query, table).Scan(&count)
+	return count > 0, err
+}
+
+// Check if a table has a column. Used to upgrade older databases.
+func hasColumn(db dbtx, table string, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("SELECT * FROM %s LIMIT 0", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return false, err
+	}
+	for _, name := range columns {
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Add a column to a table created by an older version
+func addMissingColumn(db dbtx, table string, column string, definition string) error {
+	found, err := hasColumn(db, table, column)
+	if err != nil || found {
+		return err
+	}
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	if err != nil {
+		return fmt.Errorf("failed to add %s.%s: %v", table, column, err)
+	}
+	return nil
+}
+
+// Migration 1
+func createTables(tx dbtx) error {
+	tables := []string{
+		// Scores
+		createScoresTable,
+		// Guess rows from each score's emoji grid
+		`CREATE TABLE IF NOT EXISTS guesses (
+			score_id TEXT,
+			board INTEGER,
+			row INTEGER,
+			tiles TEXT,
+			UNIQUE (score_id, board, row)
+		)`,
+		// Game specific results for each score
+		`CREATE TABLE IF NOT EXISTS score_details (
+			score_id TEXT,
+			name TEXT,
+			value TEXT,
+			UNIQUE (score_id, name)
+		)`,
+		// Channels
+		`CREATE TABLE IF NOT EXISTS channels (
+			channel_id TEXT UNIQUE,
+			guild_id TEXT,
+			name TEXT
+		)`,
+		// Messages that looked like scores but didn't parse
+		`CREATE TABLE IF NOT EXISTS unparsed (
+			message_id TEXT UNIQUE,
+			channel_id TEXT,
+			username TEXT,
+			content TEXT,
+			error TEXT,
+			status TEXT
+		)`,
+		// Puzzles
+		createPuzzlesTable,
+	}
+	for _, table := range tables {
+		_, err := tx.Exec(table)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Game numbers and scores are integers so "1,327" and "01327" are the same puzzle and stats sort
+// numerically. The text as shared is kept for display.
+const createScoresTable = `
+	CREATE TABLE IF NOT EXISTS scores (
+		id TEXT UNIQUE,
+		message_id TEXT,
+		channel_id TEXT,
+		username TEXT,
+		game TEXT,
+		game_number INTEGER,
+		score INTEGER,
+		win TEXT,
+		hardmode TEXT,
+		content TEXT,
+		game_number_text TEXT,
+		score_text TEXT,
+		UNIQUE (username, game, game_number, score)
+	)
+`
+
+const createPuzzlesTable = `
+	CREATE TABLE IF NOT EXISTS puzzles (
+		game TEXT,
+		game_number INTEGER,
+		date TEXT,
+		solution TEXT,
+		UNIQUE (game, game_number)
+	)
+`
+
+// Migration 2. Scores used to be keyed by message ID alone. Move that to message_id and key scores by
+// message and game.
+func migrateScoreIDs(tx dbtx) error {
+	found, err := hasColumn(tx, "scores", "message_id")
+	if err != nil {
+		return err
+	}
+	if !found {
+		_, err = tx.Exec("ALTER TABLE scores ADD COLUMN message_id TEXT")
+		if err != nil {
+			return fmt.Errorf("failed to add message_id: %v", err)
+		}
+		_, err = tx.Exec("UPDATE scores SET message_id = id, id = id || ':' || game")
+		if err != nil {
+			return fmt.Errorf("failed to migrate score ids: %v", err)
+		}
+	}
+	_, err = tx.Exec("CREATE INDEX IF NOT EXISTS scores_message_id ON scores (message_id)")
+	return err
+}
+
+// Migration 4. Older SQLite databases stored game numbers and scores as text. Rebuild scores and puzzles with
+// integer columns, keeping the original text for display. Scores and puzzles that only differed by
+// formatting are merged, keeping the earliest.
+func migrateNumericScores(tx dbtx) error {
+	found, err := hasColumn(tx, "scores", "score_text")
+	if err != nil || found {
+		return err
+	}
+	number := "CAST(REPLACE(REPLACE(REPLACE(game_number, ',', ''), '.', ''), ' ', '') AS INTEGER)"
+	steps := []string{
+		"ALTER TABLE scores RENAME TO scores_text",
+		createScoresTable,
+		`INSERT OR IGNORE INTO scores (id, message_id, channel_id, username, game, game_number, score, win, hardmode, content, game_number_text, score_text)
+			SELECT id, message_id, channel_id, username, game, ` + number + `, CAST(score AS INTEGER), win, hardmode, content, game_number, score
+			FROM scores_text
+			ORDER BY message_id`,
+		"DROP TABLE scores_text",
+		"CREATE INDEX IF NOT EXISTS scores_message_id ON scores (message_id)",
+		"ALTER TABLE puzzles RENAME TO puzzles_text",
+		createPuzzlesTable,
+		`INSERT OR IGNORE INTO puzzles (game, game_number, date, solution)
+			SELECT game, ` + number + `, date, solution
+			FROM puzzles_text
+			ORDER BY date`,
+		"DROP TABLE puzzles_text",
+	}
+	for _, step := range steps {
+		_, err = tx.Exec(step)
+		if err != nil {
+			return fmt.Errorf("failed to migrate numeric scores: %v", err)
+		}
+	}
+	return nil
+}
+
+// Migration 5. Scores reference the poster's Discord user ID so renames don't split their history and
+// people with the same name aren't merged. Existing scores only have a username until a rescan fills in
+// player_id (see the backfill command). Scores are rebuilt so the duplicate check includes the player.
+func migratePlayers(tx dbtx) error {
+	steps := []string{
+		// Last seen is the message ID (snowflake) the name came from, so older messages don't rename a player
+		`CREATE TABLE IF NOT EXISTS players (
+			player_id TEXT UNIQUE,
+			username TEXT,
+			display_name TEXT,
+			last_seen BIGINT
+		)`,
+		`CREATE TABLE IF NOT EXISTS player_names (
+			player_id TEXT,
+			username TEXT,
+			display_name TEXT,
+			first_seen BIGINT,
+			last_seen BIGINT,
+			UNIQUE (player_id, username, display_name)
+		)`,
+		"ALTER TABLE scores RENAME TO scores_v4",
+		`CREATE TABLE scores (
+			id TEXT UNIQUE,
+			message_id TEXT,
+			channel_id TEXT,
+			player_id TEXT,
+			username TEXT,
+			game TEXT,
+			game_number INTEGER,
+			score INTEGER,
+			win TEXT,
+			hardmode TEXT,
+			content TEXT,
+			game_number_text TEXT,
+			score_text TEXT,
+			UNIQUE (player_id, username, game, game_number, score)
+		)`,
+		`INSERT INTO scores (id, message_id, channel_id, username, game, game_number, score, win, hardmode, content, game_number_text, score_text)
+			SELECT id, message_id, channel_id, username, game, game_number, score, win, hardmode, content, game_number_text, score_text
+			FROM scores_v4`,
+		"DROP TABLE scores_v4",
+		"CREATE INDEX IF NOT EXISTS scores_message_id ON scores (message_id)",
+		"CREATE INDEX IF NOT EXISTS scores_player_id ON scores (player_id)",
+	}
+	for _, step := range steps {
+		_, err := tx.Exec(step)
+		if err != nil {
+			return fmt.Errorf("failed to migrate players: %v", err)
+		}
+	}
+	return addMissingColumn(tx, "unparsed", "player_id", "TEXT")
+}
+
+// Migration 7. Puzzles are dated from their number for games with an epoch, or games numbered by date
+// (YYYYMMDD), and otherwise from the earliest post. The epochs are a copy of the ones this migration shipped
+// with, so every database gets the same dates. Epochs added since are applied by reparse.
+func migratePuzzleDates(tx dbtx) error {
+	epochs := map[string]string{"Wordle": "2021-06-19", "Connections": "2023-06-11", "Strands": "2024-03-03"}
+	type puzzle struct {
+		game   string
+		number int
+	}
+	dates := map[puzzle]string{}
+	rows, err := tx.Query("SELECT game, game_number, message_id FROM scores")
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var p puzzle
+		var messageID string
+		err := rows.Scan(&p.game, &p.number, &messageID)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		if day, err := time.Parse("20060102", strconv.Itoa(p.number)); err == nil && p.number >= 19000101 && p.number <= 29991231 {
+			dates[p] = day.Format("2006-01-02")
+			continue
+		}
+		if epoch, ok := epochs[p.game]; ok {
+			day, err := time.Parse("2006-01-02", epoch)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			dates[p] = day.AddDate(0, 0, p.number).Format("2006-01-02")
+			continue
+		}
+		date, err := dateFromDiscordSnowflake(messageID)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		if previous, ok := dates[p]; !ok || date < previous {
+			dates[p] = date
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	// Puzzles are read before updating since PostgreSQL can't run a query while reading another
+	var changed []puzzle
+	rows, err = tx.Query("SELECT game, game_number, date FROM puzzles")
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var p puzzle
+		var date string
+		err := rows.Scan(&p.game, &p.number, &date)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		if repaired, ok := dates[p]; ok && repaired != date {
+			changed = append(changed, p)
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	for _, p := range changed {
+		_, err = tx.Exec("UPDATE puzzles SET date = ? WHERE game = ? AND game_number = ?", dates[p], p.game, p.number)
+		if err != nil {
+			return fmt.Errorf("failed to migrate puzzle dates: %v", err)
+		}
+	}
+	return nil
+}
+
+// Migration 8. Every insert, replacement and deletion of a score, with JSON snapshots of the score before
+// and after.
+func createScoreAudit(tx dbtx) error {
+	steps := []string{
+		`CREATE TABLE IF NOT EXISTS score_audit (
+			score_id TEXT,
+			action TEXT,
+			old_score TEXT,
+			new_score TEXT,
+			source TEXT,
+			changed TEXT,
+			UNIQUE (score_id, changed, action)
+		)`,
+		"CREATE INDEX IF NOT EXISTS score_audit_changed ON score_audit (changed)",
+	}
+	for _, step := range steps {
+		_, err := tx.Exec(step)
+		if err != nil {
+			return fmt.Errorf("failed to create score audit: %v", err)
+		}
+	}
+	return nil
+}
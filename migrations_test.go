@@ -0,0 +1,149 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Open an empty database in a temporary directory
+func openTestDatabase(t *testing.T) *database {
+	t.Helper()
+	db, err := openDatabase(filepath.Join(t.TempDir(), "scores.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	db := openTestDatabase(t)
+	// Schema from before scores were keyed by message and game
+	_, err := db.Exec(`
+		CREATE TABLE scores (id TEXT UNIQUE, channel_id TEXT, username TEXT, game TEXT, game_number TEXT, score TEXT, win TEXT, hardmode TEXT, UNIQUE (username, game, game_number, score));
+		CREATE TABLE puzzles (game TEXT, game_number TEXT, date TEXT, solution TEXT, UNIQUE (game, game_number));
+		INSERT INTO scores VALUES ('1300000000000000000', 'c1', 'bob', 'Wordle', '1,327', '4', 'Y', '');
+		INSERT INTO scores VALUES ('1300000000000000001', 'c1', 'bob', 'Wordle', '01327', '4', 'Y', '');
+		INSERT INTO puzzles VALUES ('Wordle', '1,327', '2025-01-01', NULL), ('Wordle', '1327', '2025-01-02', NULL);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = migrateDatabase(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, err := schemaVersion(db)
+	if err != nil || version != latestSchemaVersion() {
+		t.Fatalf("expected version %d, got %d: %v", latestSchemaVersion(), version, err)
+	}
+	var id, messageID, text string
+	var number, count int
+	err = db.QueryRow("SELECT id, message_id, game_number, game_number_text, COUNT(*) FROM scores").Scan(&id, &messageID, &number, &text, &count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "1300000000000000000:Wordle" || messageID != "1300000000000000000" || number != 1327 || text != "1,327" || count != 1 {
+		t.Errorf("unexpected score %s %s %d %s (%d rows)", id, messageID, number, text, count)
+	}
+	var date string
+	err = db.QueryRow("SELECT date, COUNT(*) FROM puzzles").Scan(&date, &count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Repaired from the day it was posted to the Wordle epoch
+	if date != "2025-02-05" || count != 1 {
+		t.Errorf("unexpected puzzle %s (%d rows)", date, count)
+	}
+	// Running again is a no-op
+	err = migrateDatabase(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewerDatabaseRefused(t *testing.T) {
+	db := openTestDatabase(t)
+	err := migrateDatabase(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec("INSERT INTO schema_version (version, name) VALUES (?, 'future')", latestSchemaVersion()+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = migrateDatabase(db)
+	if err == nil {
+		t.Error("expected a newer database to be refused")
+	}
+}
+
+func TestMigrationStatusOnlyReads(t *testing.T) {
+	db := openTestDatabase(t)
+	var out strings.Builder
+	err := printMigrationStatus(db, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Schema version 0") {
+		t.Errorf("expected a new database at version 0:\n%s", out.String())
+	}
+	exists, err := hasTable(db, "schema_version")
+	if err != nil || exists {
+		t.Errorf("expected schema_version left uncreated: %v", err)
+	}
+	err = migrateDatabase(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	err = printMigrationStatus(db, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "pending") {
+		t.Errorf("expected every migration applied:\n%s", out.String())
+	}
+
+	// A file that isn't a database is reported rather than shown as empty
+	name := filepath.Join(t.TempDir(), "notes.txt")
+	err = os.WriteFile(name, []byte(strings.Repeat("not a database\n", 100)), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := openDatabase(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	if printMigrationStatus(other, &strings.Builder{}) == nil {
+		t.Error("expected a file that isn't a database to be refused")
+	}
+}
+
+// Migration 7 keeps the epochs it shipped with, so games given one since are left to reparse
+func TestMigratePuzzleDatesIsFixed(t *testing.T) {
+	memory := useMemoryStore(t)
+	addTestScores(t, map[string][]string{"bob": {"Wordle 1,327 4/6", "Pips #46 Easy 🟢\n1:03"}})
+	_, err := memory.db.Exec("UPDATE puzzles SET date = '2025-02-09'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = migratePuzzleDates(memory.db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"Wordle": "2025-02-05", "Pips Easy": "2025-02-05"}
+	for game, date := range expected {
+		var saved string
+		err := memory.db.QueryRow("SELECT date FROM puzzles WHERE game = ?", game).Scan(&saved)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if saved != date {
+			t.Errorf("expected %s on %s, got %s", game, date, saved)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -10,170 +12,365 @@ import (
 )
 
 type Score struct {
-	ID         string
+	ID         string // Message ID and game. A message can hold scores for several games.
+	MessageID  string
 	ChannelID  string
+	PlayerID   string // Discord user ID. Empty for scores saved before IDs were recorded.
 	Username   string
+	Name       string // Display name when posted
 	Game       string
-	GameNumber string
-	Score      string
+	GameNumber string // As shared, for display ("1,327")
+	Number     int    // GameNumber as an integer, used to store and look up puzzles
+	Score      string // As normalized by the parser, for display
+	Value      int    // Score as an integer, used for stats
 	Win        string
 	Hardmode   string
+	Guesses    []Guess
+	Details    map[string]string // Game specific results (mistakes, solve order, etc...)
+	Content    string            // Original message, for reparsing
 }
 
-// Parse a score from a text message (string)
-func ParseScoreFromContent(content string) (*Score, error) {
-	lines := strings.Split(content, "\n")
-	if len(lines) == 0 {
-		return nil, fmt.Errorf("message content is blank")
+// One row of an emoji grid
+type Guess struct {
+	Board int    // Board number for multi-board games, starting at 1
+	Row   int    // Guess number on the board, starting at 1
+	Tiles string // One letter per tile: G (green), Y (yellow) or - (gray)
+}
+
+// Parser for the share text of a single game (or family of similar games)
+type GameParser interface {
+	// Name of the game, used for logging and lookups
+	Name() string
+	// Find where this game's share starts in content. Returns -1 if it is not present.
+	Match(content string) int
+	// Pull the raw game, number and score out of a share
+	Extract(content string) (*Score, error)
+	// Apply the game's rules (fail penalty, win flag, etc...) to an extracted score
+	Normalize(score *Score) error
+}
+
+var gameParsers []GameParser
+
+// How to read a game's scores
+type GameInfo struct {
+	Unit           string // What the score counts: guesses, seconds, points, etc...
+	HigherIsBetter bool
+	Epoch          string // Date of puzzle 0 (the day before #1) for games with one puzzle a day
+}
+
+var gameInfos = map[string]GameInfo{}
+
+// Describe a game's scores. Games without info are guesses where lower is better.
+func registerGameInfo(game string, info GameInfo) {
+	gameInfos[game] = info
+}
+
+// Look up info by game name. Variants like "Daily Quordle" fall back to their base game.
+func getGameInfo(game string) GameInfo {
+	info, ok := gameInfos[game]
+	if ok {
+		return info
 	}
-	game := ""
-	game_no := ""
-	hardmode := ""
-	win := ""
-	score_value := lines[0]
-	patterns := []*regexp.Regexp{
-		regexp.MustCompile(`(?s)(?P<game>Wordle) (?P<game_no>[\d,]+) (?P<score>\w)\/6(?P<hardmode>[*]?)`),
-		regexp.MustCompile(`(?s)(?P<game>[A-Za-z ]*Dordle) (?P<game_no>\d+) (?P<left>\w)[&](?P<right>\w)\/7`),
-		regexp.MustCompile(`(?s)(?P<game>[A-Za-z ]*Octordle) #(?P<game_no>\d+).*Score[:] (?P<score>\d+)`),
-		regexp.MustCompile(`(?s)(?P<game>Connections).*Puzzle #(?P<game_no>\d+)`),
-		regexp.MustCompile(`(?s)(?P<game>Tradle) #(?P<game_no>\d+).*(?P<score>[123456X])\/6`),
-		regexp.MustCompile(`(?s)(?P<game>Strands) #(?P<game_no>\d+).*`),
-		regexp.MustCompile(`(?s).*(?P<game>Animal) #(?P<game_no>\d+).*`),
-		regexp.MustCompile(`(?s)(?P<game>Zip) #(?P<game_no>\d+).*`),
-		regexp.MustCompile(`(?s)(?P<game>Mini Sudoku) #(?P<game_no>\d+).*`),
-	}
-	var captures map[string]string
-	for _, re := range patterns {
-		match := re.FindStringSubmatch(content)
-		if match != nil {
-			captures = make(map[string]string)
-			names := re.SubexpNames()
-			for i, name := range names {
-				switch name {
-				case "game":
-					game = match[i]
-				case "game_no":
-					game_no = match[i]
-				case "hardmode":
-					hardmode = match[i]
-				case "score":
-					score_value = match[i]
-				}
-				captures[name] = match[i]
-			}
-			break // patterns
+	for name, info := range gameInfos {
+		if strings.Contains(game, name) {
+			return info
 		}
 	}
-	if captures == nil {
+	return GameInfo{Unit: "guesses"}
+}
+
+// Add a parser to the registry. Parsers are tried in the order they are registered.
+func registerGameParser(parser GameParser) {
+	gameParsers = append(gameParsers, parser)
+}
+
+// Find a registered parser by name
+func getGameParser(name string) GameParser {
+	for _, parser := range gameParsers {
+		if parser.Name() == name {
+			return parser
+		}
+	}
+	return nil
+}
+
+// Run a single parser over content
+func parseGame(parser GameParser, content string) (*Score, error) {
+	score, err := parser.Extract(content)
+	if err != nil {
+		return nil, err
+	}
+	err = parser.Normalize(score)
+	if err != nil {
+		return nil, err
+	}
+	err = normalizeNumbers(score)
+	if err != nil {
+		return nil, err
+	}
+	return score, nil
+}
+
+// Read a puzzle number or score, ignoring thousands separators and leading zeros
+func parseNumber(text string) (int, error) {
+	text = strings.NewReplacer(",", "", ".", "", " ", "", "\u00a0", "", "\u202f", "").Replace(text)
+	return strconv.Atoi(text)
+}
+
+// Fill in the integer game number and score. Games without a puzzle number are numbered later.
+func normalizeNumbers(score *Score) error {
+	var err error
+	score.Number = 0
+	if score.GameNumber != "" {
+		score.Number, err = parseNumber(score.GameNumber)
+		if err != nil {
+			return fmt.Errorf("%s game number %q is not a number", score.Game, score.GameNumber)
+		}
+	}
+	score.Value, err = parseNumber(score.Score)
+	if err != nil {
+		return fmt.Errorf("%s score %q is not a number", score.Game, score.Score)
+	}
+	return nil
+}
+
+// Regex matcher shared by most parsers. Patterns should capture at least `game` and `game_no`.
+type pattern struct {
+	re *regexp.Regexp
+}
+
+func newPattern(expr string) pattern {
+	return pattern{re: regexp.MustCompile(expr)}
+}
+
+// Start of the first match
+func (p pattern) Match(content string) int {
+	loc := p.re.FindStringIndex(content)
+	if loc == nil {
+		return -1
+	}
+	return loc[0]
+}
+
+// Named captures for the first match in content
+func (p pattern) captures(content string) (map[string]string, error) {
+	match := p.re.FindStringSubmatch(content)
+	if match == nil {
 		return nil, fmt.Errorf("message did not parse: %s", content)
 	}
-	switch {
-	case game == "Wordle" || game == "Tradle":
-		if score_value == "X" {
-			score_value = "7"
-			win = "N"
-		} else {
-			win = "Y"
+	captures := make(map[string]string)
+	for i, name := range p.re.SubexpNames() {
+		if name != "" {
+			captures[name] = match[i]
+		}
+	}
+	return captures, nil
+}
+
+// Score for the common "Game #N ..." captures
+func (p pattern) extract(content string) (*Score, map[string]string, error) {
+	captures, err := p.captures(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	score := Score{
+		Game:       captures["game"],
+		GameNumber: captures["game_no"],
+		Hardmode:   captures["hardmode"],
+		Score:      captures["score"],
+	}
+	return &score, captures, nil
+}
+
+var solveTime = regexp.MustCompile(`(?:(\d+):)?(\d+):(\d{2})`)
+
+// Seconds for the first h:mm:ss or m:ss time in text
+func parseSolveTime(text string) (string, error) {
+	match := solveTime.FindStringSubmatch(text)
+	if match == nil {
+		return "", fmt.Errorf("no time found: %s", text)
+	}
+	hours, _ := strconv.Atoi(match[1])
+	minutes, _ := strconv.Atoi(match[2])
+	seconds, _ := strconv.Atoi(match[3])
+	return strconv.Itoa(hours*3600 + minutes*60 + seconds), nil
+}
+
+// Y/N flag as stored in the database
+func yesNo(value bool) string {
+	if value {
+		return "Y"
+	}
+	return "N"
+}
+
+// Shared rule for "x/N" games: X is a loss worth penalty
+func normalizeFailPenalty(score *Score, penalty string) {
+	if score.Score == "X" {
+		score.Score = penalty
+		score.Win = "N"
+	} else {
+		score.Win = "Y"
+	}
+}
+
+// Five letter rows in light (⬜) or dark (⬛) mode
+var gridRow = regexp.MustCompile(`[🟩🟨⬛⬜]{5}`)
+
+// Read guess rows from an emoji grid. Rows for multi-board games are interleaved left to right.
+// Boards are padded with blank rows once solved, so anything after a solve is skipped.
+func parseGrid(content string, boards int) []Guess {
+	content = strings.ReplaceAll(content, "\uFE0F", "")
+	var guesses []Guess
+	solved := make([]bool, boards)
+	rows := make([]int, boards)
+	for i, row := range gridRow.FindAllString(content, -1) {
+		board := i % boards
+		if solved[board] {
+			continue
 		}
-	case strings.Contains(game, "Dordle"):
-		left := captures["left"]
-		right := captures["right"]
-		value := 0
-		if left == "X" {
-			value += 7
-			win = "N"
-		} else {
-			left_value, _ := strconv.Atoi(left)
-			value += left_value
+		var tiles strings.Builder
+		for _, tile := range row {
+			switch tile {
+			case '🟩':
+				tiles.WriteString("G")
+			case '🟨':
+				tiles.WriteString("Y")
+			default:
+				tiles.WriteString("-")
+			}
 		}
-		if right == "X" {
-			value += 7
-			win = "N"
-		} else {
-			right_value, _ := strconv.Atoi(right)
-			value += right_value
+		rows[board]++
+		guesses = append(guesses, Guess{Board: board + 1, Row: rows[board], Tiles: tiles.String()})
+		if tiles.String() == "GGGGG" {
+			solved[board] = true
 		}
-		if win == "" {
-			win = "Y"
+	}
+	return guesses
+}
+
+// Parse every score from a text message (string). Scores are returned in the order they appear.
+func ParseScoreFromContent(content string) ([]Score, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, fmt.Errorf("message content is blank")
+	}
+	type share struct {
+		start  int
+		parser GameParser
+	}
+	var shares []share
+	for _, parser := range gameParsers {
+		start := parser.Match(content)
+		if start >= 0 {
+			shares = append(shares, share{start: start, parser: parser})
 		}
-		score_value = strconv.Itoa(value)
-	case strings.Contains(game, "Octordle"):
-		if strings.Contains(content, "🟥") {
-			win = "N"
-		} else {
-			win = "Y"
+	}
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("message did not parse: %s", content)
+	}
+	// Each share runs until the next one starts. Ties go to the parser registered first.
+	sort.SliceStable(shares, func(i, j int) bool {
+		return shares[i].start < shares[j].start
+	})
+	var scores []Score
+	var errs []error
+	for i, item := range shares {
+		if i > 0 && item.start == shares[i-1].start {
+			continue
 		}
-	case game == "Connections":
-		re := regexp.MustCompile("(?s)[🟨🟩🟪🟦]+")
-		lines := re.FindAllString(content, 64)
-		match := 0
-		total := 0
-		for _, line := range lines {
-			if line == "🟨🟨🟨🟨" || line == "🟩🟩🟩🟩" || line == "🟪🟪🟪🟪" || line == "🟦🟦🟦🟦" {
-				match++
+		end := len(content)
+		for _, next := range shares[i+1:] {
+			if next.start > item.start {
+				end = next.start
+				break
 			}
-			total++
-		}
-		if match == 4 {
-			win = "Y"
-			score_value = strconv.Itoa(total)
-		} else {
-			win = "N"
-			score_value = "7"
 		}
-	case game == "Strands":
-		score_value = strconv.Itoa(strings.Count(content, "💡"))
-		win = "Y"
-	case game == "Animal":
-		score_value = strconv.Itoa(strings.Count(content, "🟧") + strings.Count(content, "🟩") + strings.Count(content, "🟥"))
-		if score_value == "20" {
-			win = "N"
-		} else {
-			win = "Y"
+		score, err := parseGame(item.parser, content[item.start:end])
+		if err != nil {
+			errs = append(errs, err)
+			continue
 		}
-	case game == "Zip" || game == "Mini Sudoku":
-		re := regexp.MustCompile(`(?s)(\d+):(\d+)`)
-		match := re.FindStringSubmatch(content)
-		minutes, _ := strconv.Atoi(match[1])
-		seconds, _ := strconv.Atoi(match[2])
-		score_value = strconv.Itoa(minutes * 60 + seconds)
-		win = "Y"
+		scores = append(scores, *score)
 	}
-
-	score := Score{
-		Game:       game,
-		GameNumber: game_no,
-		Hardmode:   hardmode,
-		Score:      score_value,
-		Win:        win,
+	if len(scores) == 0 {
+		return nil, errs[0]
 	}
-	return &score, nil
+	// Shares that failed are reported along with the ones that parsed
+	return scores, errors.Join(errs...)
 }
 
-// Parse Discord message to extract score
-func ParseScoreFromMessage(msg *discordgo.Message) (*Score, error) {
+// Parse Discord message to extract scores. When some shares in a message fail to parse, the scores that
+// did are returned along with the error.
+func ParseScoreFromMessage(msg *discordgo.Message) ([]Score, error) {
 	if msg.Type != 0 {
 		return nil, fmt.Errorf("message is not a score")
 	}
-	score, err := ParseScoreFromContent(msg.Content)
-	if err != nil {
+	scores, err := ParseScoreFromContent(msg.Content)
+	if len(scores) == 0 {
 		return nil, err
 	}
-	score.ID = msg.ID
-	score.ChannelID = msg.ChannelID
-	score.Username = msg.Author.Username
-	return score, nil
+	for i := range scores {
+		// Games without puzzle numbers are numbered by the day they were posted
+		if scores[i].GameNumber == "" {
+			date, err := dateFromDiscordSnowflake(msg.ID)
+			if err != nil {
+				return nil, err
+			}
+			scores[i].GameNumber = strings.ReplaceAll(date, "-", "")
+			scores[i].Number, _ = parseNumber(scores[i].GameNumber)
+		}
+		scores[i].ID = scoreID(msg.ID, scores[i].Game)
+		scores[i].MessageID = msg.ID
+		scores[i].ChannelID = msg.ChannelID
+		scores[i].PlayerID = msg.Author.ID
+		scores[i].Username = msg.Author.Username
+		scores[i].Name = displayName(msg.Author)
+		scores[i].Content = msg.Content
+	}
+	return scores, err
+}
+
+// Name shown for a Discord user: their global display name if they set one
+func displayName(user *discordgo.User) string {
+	if user.GlobalName != "" {
+		return user.GlobalName
+	}
+	return user.Username
+}
+
+// Key used to group a player's scores. Scores from before IDs were recorded are grouped by username.
+func (score Score) Player() string {
+	if score.PlayerID != "" {
+		return score.PlayerID
+	}
+	return score.Username
+}
+
+// Name to show for the player
+func (score Score) DisplayName() string {
+	if score.Name != "" {
+		return score.Name
+	}
+	return score.Username
+}
+
+// Unique ID for a score
+func scoreID(messageID string, game string) string {
+	return messageID + ":" + game
 }
 
 func ParseScores(messages []*discordgo.Message) ([]Score, error) {
 	scores := make([]Score, 0, len(messages))
 	for _, msg := range messages {
-		score, err := ParseScoreFromMessage(msg)
+		parsed, err := ParseScoreFromMessage(msg)
 		if err != nil {
 			logPrintln("%v", err)
-			continue
+			err = addUnparsedMessage(msg, err, len(parsed) > 0)
+			if err != nil {
+				logPrintln("%v", err)
+			}
 		}
-		scores = append(scores, *score)
+		scores = append(scores, parsed...)
 	}
 	return scores, nil
 }
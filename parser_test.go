@@ -1,7 +1,11 @@
 package main
 
 import (
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/bwmarrin/discordgo"
 )
 
 // Check correctness of incoming score parsing
@@ -47,6 +51,10 @@ func TestScoreParser(t *testing.T) {
 			input:  "Daily Sequence Octordle #563 4️⃣5️⃣ 7️⃣8️⃣ 9️⃣🔟 🕚🕛 Score: 66",
 			output: Score{Game: "Daily Sequence Octordle", Score: "66", GameNumber: "563", Win: "Y"},
 		},
+		{
+			input:  "Daily Duotrigordle #1130\nGuesses: 37/37\nTime: 10:22.12\n07 08 09 10 11 12 13 14\n15 16 17 18 19 20 21 22\n23 24 25 26 27 28 29 30\n31 32 33 34 35 36 37 🟥\nhttps://duotrigordle.com/",
+			output: Score{Game: "Daily Duotrigordle", Score: "720", GameNumber: "1130", Win: "N"},
+		},
 		{
 			input:  "Connections \nPuzzle #51\n🟨🟨🟨🟨\n🟩🟩🟩🟩\n🟪🟪🟪🟪\n🟦🟦🟦🟦",
 			output: Score{Game: "Connections", Score: "4", GameNumber: "51", Win: "Y"},
@@ -113,10 +121,14 @@ func TestScoreParser(t *testing.T) {
 		},
 	}
 	for _, item := range data {
-		score, err := ParseScoreFromContent(item.input)
+		scores, err := ParseScoreFromContent(item.input)
 		if err != nil {
 			t.Fatalf(`TestScoreParser("%s") returned error: %v`, item.input, err)
 		}
+		if len(scores) != 1 {
+			t.Fatalf("TestScoreParser [Count]\n%s\nReturned %d scores, expected 1", item.input, len(scores))
+		}
+		score := scores[0]
 		if score.Game != item.output.Game {
 			t.Fatalf("TestScoreParser [Game]\n%s\nReturned:\n%s\nExpected:\n%s", item.input, score.Game, item.output.Game)
 		}
@@ -134,3 +146,198 @@ func TestScoreParser(t *testing.T) {
 		}
 	}
 }
+
+// Compare scores. Guesses and details are only checked when the expected score has them.
+func sameScore(returned Score, expected Score) bool {
+	if expected.Guesses == nil {
+		returned.Guesses = nil
+	}
+	if expected.Details == nil {
+		returned.Details = nil
+	}
+	if expected.Number == 0 && expected.Value == 0 {
+		returned.Number = 0
+		returned.Value = 0
+	}
+	return reflect.DeepEqual(returned, expected)
+}
+
+type parserCase struct {
+	input  string
+	output Score
+}
+
+// Run share text through a single game parser and compare the results
+func checkGameParser(t *testing.T, parser GameParser, cases []parserCase) {
+	t.Helper()
+	for _, item := range cases {
+		if parser.Match(item.input) < 0 {
+			t.Fatalf("%s did not match:\n%s", parser.Name(), item.input)
+		}
+		score, err := parseGame(parser, item.input)
+		if err != nil {
+			t.Fatalf("%s returned error: %v", parser.Name(), err)
+		}
+		if !sameScore(*score, item.output) {
+			t.Fatalf("%s\n%s\nReturned:\n%+v\nExpected:\n%+v", parser.Name(), item.input, *score, item.output)
+		}
+	}
+}
+
+// Parsers should only claim their own shares
+func checkGameParserRejects(t *testing.T, parser GameParser, inputs []string) {
+	t.Helper()
+	for _, input := range inputs {
+		if parser.Match(input) >= 0 {
+			t.Fatalf("%s should not match:\n%s", parser.Name(), input)
+		}
+	}
+}
+
+func TestGameParserRegistry(t *testing.T) {
+	for _, name := range []string{"Wordle", "Dordle", "Octordle", "Connections", "Tradle", "Strands", "Animal", "Zip", "Mini Sudoku"} {
+		if getGameParser(name) == nil {
+			t.Fatalf("%s parser is not registered", name)
+		}
+	}
+	_, err := ParseScoreFromContent("Good morning!")
+	if err == nil {
+		t.Fatalf("ParseScoreFromContent should fail on plain text")
+	}
+}
+
+func TestMultipleScores(t *testing.T) {
+	content := "Wordle 1,327 4/6\n⬜🟩🟨⬜⬜\n⬜⬜🟨⬜⬜\n⬜⬜⬜⬜🟨\n🟩🟩🟩🟩🟩\n\n" +
+		"Connections\nPuzzle #613\n🟨🟩🟨🟨\n🟨🟨🟨🟨\n🟦🟦🟦🟦\n🟩🟩🟩🟩\n🟪🟪🟪🟪\n\n" +
+		"Strands #448\n“Get out the dust buster!”\n🔵💡🔵💡\n🔵💡🔵🟡\n💡🔵"
+	expected := []Score{
+		{Game: "Wordle", GameNumber: "1,327", Score: "4", Win: "Y"},
+		{Game: "Connections", GameNumber: "613", Score: "5", Win: "Y"},
+		{Game: "Strands", GameNumber: "448", Score: "4", Win: "Y"},
+	}
+	scores, err := ParseScoreFromContent(content)
+	if err != nil {
+		t.Fatalf("ParseScoreFromContent returned error: %v", err)
+	}
+	if len(scores) != len(expected) {
+		t.Fatalf("ParseScoreFromContent returned %d scores, expected %d: %+v", len(scores), len(expected), scores)
+	}
+	for i := range expected {
+		if !sameScore(scores[i], expected[i]) {
+			t.Fatalf("ParseScoreFromContent score %d\nReturned:\n%+v\nExpected:\n%+v", i, scores[i], expected[i])
+		}
+	}
+}
+
+func TestPartialScores(t *testing.T) {
+	content := "Wordle 1,327 4/6\n\nConnections\nPuzzle #613\n🟨🟨🟨🟨\n🟦🟦🟦🟦\n🟩🟩🟩🟩\n🟪🟪🟪🟪\n\nDaily Quordle 1002\n6️⃣4️⃣"
+	scores, err := ParseScoreFromContent(content)
+	if len(scores) != 2 || scores[0].Game != "Wordle" || scores[1].Game != "Connections" {
+		t.Fatalf("expected Wordle and Connections to parse, got %+v", scores)
+	}
+	if err == nil || !strings.Contains(err.Error(), "found 2 of 4 boards") {
+		t.Fatalf("expected the Quordle failure to be reported, got %v", err)
+	}
+}
+
+func TestScoreIDs(t *testing.T) {
+	msg := discordgo.Message{
+		ID:        "1234",
+		ChannelID: "99",
+		Content:   "Wordle 771 3/6*\n\nTradle #527 2/6",
+		Author:    &discordgo.User{Username: "mindari"},
+	}
+	scores, err := ParseScoreFromMessage(&msg)
+	if err != nil {
+		t.Fatalf("ParseScoreFromMessage returned error: %v", err)
+	}
+	if len(scores) != 2 {
+		t.Fatalf("ParseScoreFromMessage returned %d scores, expected 2", len(scores))
+	}
+	if scores[0].ID != "1234:Wordle" || scores[1].ID != "1234:Tradle" {
+		t.Fatalf("ParseScoreFromMessage returned IDs %s and %s", scores[0].ID, scores[1].ID)
+	}
+	for _, score := range scores {
+		if score.MessageID != "1234" || score.ChannelID != "99" || score.Username != "mindari" {
+			t.Fatalf("ParseScoreFromMessage did not copy message info: %+v", score)
+		}
+	}
+}
+
+func TestGridParser(t *testing.T) {
+	cases := []struct {
+		input  string
+		boards int
+		output []Guess
+	}{
+		{
+			input:  "Wordle 771 3/6*\r\n\r\n⬛⬛⬛⬛🟩\r\n🟨🟩⬛⬛🟩\r\n🟩🟩🟩🟩🟩",
+			boards: 1,
+			output: []Guess{{1, 1, "----G"}, {1, 2, "YG--G"}, {1, 3, "GGGGG"}},
+		},
+		{
+			input:  "Wordle 1,327 4/6\n⬜🟩🟨⬜⬜\n⬜⬜🟨⬜⬜\n⬜⬜⬜⬜🟨\n🟩🟩🟩🟩🟩",
+			boards: 1,
+			output: []Guess{{1, 1, "-GY--"}, {1, 2, "--Y--"}, {1, 3, "----Y"}, {1, 4, "GGGGG"}},
+		},
+		{
+			input:  "Daily Dordle 0597 4&6/7 🟨🟨⬜⬜⬜ 🟨⬜🟨⬜⬜ ⬜⬜⬜⬜⬜ ⬜⬜⬜⬜⬜ ⬜⬜🟨🟨⬜ ⬜⬜⬜⬜⬜ 🟩🟩🟩🟩🟩 ⬜🟨⬜⬜⬜ ⬛⬛⬛⬛⬛ ⬜🟩🟨🟩🟩 ⬛⬛⬛⬛⬛ 🟩🟩🟩🟩🟩 zaratustra.itch.io/dordle",
+			boards: 2,
+			output: []Guess{
+				{1, 1, "YY---"}, {2, 1, "Y-Y--"},
+				{1, 2, "-----"}, {2, 2, "-----"},
+				{1, 3, "--YY-"}, {2, 3, "-----"},
+				{1, 4, "GGGGG"}, {2, 4, "-Y---"},
+				{2, 5, "-GYGG"},
+				{2, 6, "GGGGG"},
+			},
+		},
+		{
+			input:  "#Tradle #527 2/6 🟩🟩🟩🟩🟨 🟩🟩🟩🟩🟩 https://oec.world/en/tradle",
+			boards: 1,
+			output: []Guess{{1, 1, "GGGGY"}, {1, 2, "GGGGG"}},
+		},
+	}
+	for _, item := range cases {
+		guesses := parseGrid(item.input, item.boards)
+		if !reflect.DeepEqual(guesses, item.output) {
+			t.Fatalf("parseGrid\n%s\nReturned:\n%v\nExpected:\n%v", item.input, guesses, item.output)
+		}
+	}
+}
+
+func TestUnnumberedGames(t *testing.T) {
+	msg := discordgo.Message{
+		ID:      "1300000000000000000",
+		Content: "Spelling Bee\nGood Start with 12 points",
+		Author:  &discordgo.User{Username: "mindari"},
+	}
+	scores, err := ParseScoreFromMessage(&msg)
+	if err != nil {
+		t.Fatalf("ParseScoreFromMessage returned error: %v", err)
+	}
+	if scores[0].GameNumber != "20241027" {
+		t.Fatalf("ParseScoreFromMessage numbered the puzzle %s, expected 20241027", scores[0].GameNumber)
+	}
+}
+
+func TestNumberNormalization(t *testing.T) {
+	cases := []struct {
+		content string
+		number  int
+		value   int
+	}{
+		{"Wordle 0597 X/6", 597, 7},
+		{"Wordle 597 3/6", 597, 3},
+		{"Zip #0123 | 1:05", 123, 65},
+	}
+	for _, c := range cases {
+		scores, err := ParseScoreFromContent(c.content)
+		if err != nil {
+			t.Fatalf("%s: %v", c.content, err)
+		}
+		if scores[0].Number != c.number || scores[0].Value != c.value {
+			t.Errorf("%s: expected %d and %d, got %d and %d", c.content, c.number, c.value, scores[0].Number, scores[0].Value)
+		}
+	}
+}
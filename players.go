@@ -0,0 +1,345 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Someone who has posted scores
+type Player struct {
+	ID      string   // Discord user ID, or the username for scores from before IDs were recorded
+	Name    string   // Current display name
+	Names   []string // Other usernames and display names they have posted under
+	Aliases []string // Other identities (alt accounts) merged into this one
+}
+
+// Identity merged into another player
+type PlayerAlias struct {
+	AliasID    string
+	AliasName  string
+	PlayerID   string
+	PlayerName string
+	Merged     string
+}
+
+// Player key for a score (alias s) with merged identities resolved to the player they were merged into.
+// Queries using it need playerJoins, which also brings in the player's current names as pl.
+const playerKey = "COALESCE(a.player_id, s.player_id, s.username)"
+
+const playerJoins = `
+		LEFT JOIN player_aliases a
+			ON a.alias_id = COALESCE(s.player_id, s.username)
+		LEFT JOIN players pl
+			ON pl.player_id = COALESCE(a.player_id, s.player_id)`
+
+// Store empty strings as NULL
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// Record a player's names as of a message. Names from older messages go to the history without
+// replacing the current ones.
+func addPlayer(tx dbtx, playerID string, username string, name string, messageID string) error {
+	seen, err := strconv.ParseInt(messageID, 10, 64)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO players (player_id, username, display_name, last_seen)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (player_id) DO UPDATE
+		SET username = excluded.username, display_name = excluded.display_name, last_seen = excluded.last_seen
+		WHERE excluded.last_seen >= players.last_seen
+	`, playerID, username, name, seen)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO player_names (player_id, username, display_name, first_seen, last_seen)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (player_id, username, display_name) DO UPDATE
+		SET first_seen = CASE WHEN excluded.first_seen < player_names.first_seen THEN excluded.first_seen ELSE player_names.first_seen END,
+			last_seen = CASE WHEN excluded.last_seen > player_names.last_seen THEN excluded.last_seen ELSE player_names.last_seen END
+	`, playerID, username, name, seen, seen)
+	return err
+}
+
+// Look up a player by Discord user ID. Usernames and display names (current or past) are accepted
+// for older links, as are usernames on scores without an ID. Merged identities resolve to the player
+// they were merged into. Returns sql.ErrNoRows if nobody matches.
+func (s *sqlStore) GetPlayer(key string) (*Player, error) {
+	player, err := s.findPlayer(key)
+	if err != nil {
+		return nil, err
+	}
+	var into string
+	err = s.db.QueryRow("SELECT player_id FROM player_aliases WHERE alias_id = ?", player.ID).Scan(&into)
+	if err == nil {
+		player, err = s.findPlayer(into)
+	} else if err == sql.ErrNoRows {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query("SELECT alias_id FROM player_aliases WHERE player_id = ? ORDER BY alias_id", player.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var alias string
+		err := rows.Scan(&alias)
+		if err != nil {
+			return nil, err
+		}
+		player.Aliases = append(player.Aliases, alias)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return player, s.addPlayerNames(player)
+}
+
+// Look up a single identity without following merges. Players only seen through hand-entered scores
+// go by the username on their latest score.
+func (s *sqlStore) findPlayer(key string) (*Player, error) {
+	db := s.db
+	var player Player
+	err := db.QueryRow("SELECT player_id, display_name FROM players WHERE player_id = ?", key).Scan(&player.ID, &player.Name)
+	if err == sql.ErrNoRows {
+		err = db.QueryRow(`
+			SELECT p.player_id, p.display_name
+			FROM player_names n
+			JOIN players p
+				ON p.player_id = n.player_id
+			WHERE n.username = ? OR n.display_name = ?
+			ORDER BY n.last_seen DESC
+			LIMIT 1
+		`, key, key).Scan(&player.ID, &player.Name)
+	}
+	if err == sql.ErrNoRows {
+		err = db.QueryRow(`
+			SELECT COALESCE(player_id, username), username
+			FROM scores
+			WHERE player_id = ? OR (username = ? AND player_id IS NULL)
+			ORDER BY message_id DESC
+			LIMIT 1
+		`, key, key).Scan(&player.ID, &player.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &player, nil
+}
+
+// Fill in other names a player, and any identities merged into them, have posted under
+func (s *sqlStore) addPlayerNames(player *Player) error {
+	rows, err := s.db.Query(`
+		SELECT username, display_name
+		FROM player_names
+		WHERE player_id = ? OR player_id IN (SELECT alias_id FROM player_aliases WHERE player_id = ?)
+		ORDER BY last_seen DESC
+	`, player.ID, player.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	seen := map[string]bool{player.Name: true}
+	for rows.Next() {
+		var username, name string
+		err := rows.Scan(&username, &name)
+		if err != nil {
+			return err
+		}
+		for _, other := range []string{name, username} {
+			if !seen[other] {
+				seen[other] = true
+				player.Names = append(player.Names, other)
+			}
+		}
+	}
+	return rows.Err()
+}
+
+// Treat one identity (such as an alt account) as another player. Anyone already merged into it moves too.
+// An identity already merged elsewhere has to be unmerged first.
+func (s *sqlStore) MergePlayers(from string, into string) error {
+	// Not GetPlayer, which would follow a merge and move the player it was merged into
+	fromPlayer, err := s.findPlayer(from)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("player %s not found", from)
+	}
+	if err != nil {
+		return err
+	}
+	var merged string
+	err = s.db.QueryRow("SELECT player_id FROM player_aliases WHERE alias_id = ?", fromPlayer.ID).Scan(&merged)
+	if err == nil {
+		return fmt.Errorf("%s is already merged into %s, unmerge it first", from, merged)
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+	intoPlayer, err := s.GetPlayer(into)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("player %s not found", into)
+	}
+	if err != nil {
+		return err
+	}
+	if fromPlayer.ID == intoPlayer.ID {
+		return fmt.Errorf("%s and %s are already the same player", from, into)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("UPDATE player_aliases SET player_id = ? WHERE player_id = ?", intoPlayer.ID, fromPlayer.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec(
+		"INSERT INTO player_aliases (alias_id, player_id, merged) VALUES (?, ?, ?)",
+		fromPlayer.ID, intoPlayer.ID, time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Undo a merge so an identity counts as its own player again
+func (s *sqlStore) UnmergePlayer(key string) error {
+	player, err := s.findPlayer(key)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("player %s not found", key)
+	}
+	if err != nil {
+		return err
+	}
+	result, err := s.db.Exec("DELETE FROM player_aliases WHERE alias_id = ?", player.ID)
+	if err != nil {
+		return err
+	}
+	removed, _ := result.RowsAffected()
+	if removed == 0 {
+		return fmt.Errorf("%s is not merged into another player", key)
+	}
+	return nil
+}
+
+// List merged identities
+func (s *sqlStore) GetPlayerAliases() ([]PlayerAlias, error) {
+	rows, err := s.db.Query(`
+		SELECT a.alias_id, COALESCE(ap.display_name, a.alias_id), a.player_id, COALESCE(pp.display_name, a.player_id), a.merged
+		FROM player_aliases a
+		LEFT JOIN players ap
+			ON ap.player_id = a.alias_id
+		LEFT JOIN players pp
+			ON pp.player_id = a.player_id
+		ORDER BY 4, 2
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get player aliases: %v", err)
+	}
+	defer rows.Close()
+	var aliases []PlayerAlias
+	for rows.Next() {
+		var alias PlayerAlias
+		err := rows.Scan(&alias.AliasID, &alias.AliasName, &alias.PlayerID, &alias.PlayerName, &alias.Merged)
+		if err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, alias)
+	}
+	return aliases, rows.Err()
+}
+
+// Print merged identities for the players command
+func printPlayerAliases(out io.Writer) error {
+	aliases, err := store.GetPlayerAliases()
+	if err != nil {
+		return err
+	}
+	if len(aliases) == 0 {
+		fmt.Fprintln(out, "No merged players.")
+		return nil
+	}
+	for _, alias := range aliases {
+		fmt.Fprintf(out, "%s (%s) -> %s (%s)  merged %s\n", alias.AliasName, alias.AliasID, alias.PlayerName, alias.PlayerID, alias.Merged)
+	}
+	return nil
+}
+
+// Fill in player IDs for scores saved before they were recorded, using messages fetched by a rescan
+func (s *sqlStore) BackfillPlayerIDs(messages []*discordgo.Message, source string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, msg := range messages {
+		if msg.Author == nil || msg.Author.ID == "" {
+			continue
+		}
+		missing, err := readScores(tx, "message_id = ? AND player_id IS NULL", msg.ID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		result, err := tx.Exec("UPDATE scores SET player_id = ? WHERE message_id = ? AND player_id IS NULL", msg.Author.ID, msg.ID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		for i := range missing {
+			updated := missing[i]
+			updated.PlayerID = msg.Author.ID
+			err = addAudit(tx, updated.ID, &missing[i], &updated, source)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+		_, err = tx.Exec("UPDATE unparsed SET player_id = ? WHERE message_id = ? AND player_id IS NULL", msg.Author.ID, msg.ID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		updated, _ := result.RowsAffected()
+		if updated > 0 {
+			err = addPlayer(tx, msg.Author.ID, msg.Author.Username, displayName(msg.Author), msg.ID)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// Channels with scores that don't have a player ID yet
+func (s *sqlStore) GetChannelsMissingPlayerIDs() ([]string, error) {
+	rows, err := s.db.Query("SELECT DISTINCT channel_id FROM scores WHERE player_id IS NULL")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var channels []string
+	for rows.Next() {
+		var channel string
+		err := rows.Scan(&channel)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, channel)
+	}
+	return channels, rows.Err()
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Save messages posted by Discord users
+func addTestPosts(t *testing.T, posts []*discordgo.Message) {
+	t.Helper()
+	for i, msg := range posts {
+		msg.ID = testMessageID(i)
+		msg.ChannelID = "c1"
+	}
+	scores, err := ParseScores(posts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.AddScores(scores, sourceRescan)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlayerRename(t *testing.T) {
+	testStores(t, func(t *testing.T) {
+		err := store.AddChannel(&discordgo.Channel{ID: "c1", GuildID: "g1", Name: "games"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		addTestPosts(t, []*discordgo.Message{
+			{Author: &discordgo.User{ID: "1", Username: "bob"}, Content: "Wordle 1,327 4/6"},
+			{Author: &discordgo.User{ID: "1", Username: "robert", GlobalName: "Robert"}, Content: "Wordle 1,328 3/6"},
+		})
+		stats, err := store.GetStats("Wordle", "g1", testFrom, testTo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stats) != 1 || stats[0].PlayerID != "1" || stats[0].Username != "Robert" || stats[0].Count != 2 {
+			t.Fatalf("expected one history under the current name, got %+v", stats)
+		}
+		// Old links by username still work
+		player, err := store.GetPlayer("bob")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if player.ID != "1" || player.Name != "Robert" || len(player.Names) != 2 {
+			t.Errorf("unexpected player %+v", player)
+		}
+	})
+}
+
+func TestPlayersWithSameName(t *testing.T) {
+	testStores(t, func(t *testing.T) {
+		err := store.AddChannel(&discordgo.Channel{ID: "c1", GuildID: "g1", Name: "games"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		addTestPosts(t, []*discordgo.Message{
+			{Author: &discordgo.User{ID: "1", Username: "sam1", GlobalName: "Sam"}, Content: "Wordle 1,327 4/6"},
+			{Author: &discordgo.User{ID: "2", Username: "sam2", GlobalName: "Sam"}, Content: "Wordle 1,327 4/6"},
+		})
+		stats, err := store.GetStats("Wordle", "g1", testFrom, testTo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stats) != 2 || stats[0].Count != 1 || stats[1].Count != 1 {
+			t.Errorf("expected separate players, got %+v", stats)
+		}
+		friends, err := store.GetFriends("1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(friends) != 2 {
+			t.Errorf("unexpected friends %+v", friends)
+		}
+	})
+}
+
+func TestBackfillPlayerIDs(t *testing.T) {
+	testStores(t, func(t *testing.T) {
+		// Scores from before IDs were recorded
+		addTestScores(t, map[string][]string{"bob": {"Wordle 1,327 4/6"}})
+		for _, query := range []string{"UPDATE scores SET player_id = NULL", "DELETE FROM players", "DELETE FROM player_names"} {
+			_, err := store.(*sqlStore).db.Exec(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		channels, err := store.GetChannelsMissingPlayerIDs()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(channels) != 1 || channels[0] != "c1" {
+			t.Fatalf("unexpected channels %v", channels)
+		}
+		player, err := store.GetPlayer("bob")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if player.ID != "bob" {
+			t.Errorf("expected scores without an ID to go by username, got %+v", player)
+		}
+		err = store.BackfillPlayerIDs([]*discordgo.Message{
+			{ID: testMessageID(0), Author: &discordgo.User{ID: "1", Username: "bob", GlobalName: "Bob"}},
+		}, sourceBackfill)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scores, err := store.GetScoresByUser("Wordle", "1", testFrom, testTo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(scores) != 1 || scores[0].DisplayName() != "Bob" {
+			t.Errorf("unexpected scores %+v", scores)
+		}
+		channels, err = store.GetChannelsMissingPlayerIDs()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(channels) != 0 {
+			t.Errorf("expected no channels left, got %v", channels)
+		}
+	})
+}
+
+func TestMergePlayers(t *testing.T) {
+	testStores(t, func(t *testing.T) {
+		addTestScores(t, map[string][]string{
+			"bob":    {"Wordle 1,327 4/6"},
+			"bobalt": {"Wordle 1,328 2/6"},
+			"amy":    {"Wordle 1,327 3/6"},
+		})
+		err := store.MergePlayers("bobalt", "bob")
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats, err := store.GetStats("Wordle", "g1", testFrom, testTo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stats) != 2 || stats[1].PlayerID != "u-bob" || stats[1].Username != "bob" || stats[1].Count != 2 {
+			t.Fatalf("expected merged stats for bob, got %+v", stats)
+		}
+		attendance, err := store.GetAttendanceStatsForMonth("g1", testMonth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(attendance) != 2 {
+			t.Errorf("unexpected attendance %+v", attendance)
+		}
+		friends, err := store.GetFriends("u-bob")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(friends) != 2 {
+			t.Errorf("unexpected friends %+v", friends)
+		}
+		results, err := store.FindByChannelOrUsername("bob")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 1 || results[0].ID != "u-bob" {
+			t.Errorf("unexpected search results %+v", results)
+		}
+		player, err := store.GetPlayer("bobalt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if player.ID != "u-bob" || len(player.Aliases) != 1 {
+			t.Errorf("expected the alt to resolve to bob, got %+v", player)
+		}
+		if store.MergePlayers("bob", "bobalt") == nil {
+			t.Error("expected merging a player into their own alt to fail")
+		}
+		// Merging the alt again names the alt, not bob
+		if store.MergePlayers("bobalt", "amy") == nil {
+			t.Error("expected merging an alias elsewhere to fail until it is unmerged")
+		}
+		player, err = store.GetPlayer("bob")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if player.ID != "u-bob" {
+			t.Errorf("expected bob left alone, got %+v", player)
+		}
+		err = store.UnmergePlayer("bobalt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats, err = store.GetStats("Wordle", "g1", testFrom, testTo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stats) != 3 {
+			t.Errorf("expected separate players after unmerging, got %+v", stats)
+		}
+		if store.UnmergePlayer("bobalt") == nil {
+			t.Error("expected unmerging twice to fail")
+		}
+	})
+}
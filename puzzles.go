@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Date of a puzzle. Games with an epoch count days from it, and games numbered by date (YYYYMMDD) use
+// that date. Both are canonical. Other games fall back to the day the message was posted, which is
+// only a guess, so the earliest post wins.
+func puzzleDate(game string, number int, messageID string) (date string, canonical bool, err error) {
+	if day, ok := numberDate(number); ok {
+		return day, true, nil
+	}
+	// Exact match, variants like "Daily Quordle" number their puzzles differently
+	if info, ok := gameInfos[game]; ok && info.Epoch != "" {
+		epoch, err := time.Parse("2006-01-02", info.Epoch)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid epoch for %s: %v", game, err)
+		}
+		return epoch.AddDate(0, 0, number).Format("2006-01-02"), true, nil
+	}
+	date, err = dateFromDiscordSnowflake(messageID)
+	return date, false, err
+}
+
+// Date for games numbered by date, like 20241018
+func numberDate(number int) (string, bool) {
+	if number < 19000101 || number > 29991231 {
+		return "", false
+	}
+	date, err := time.Parse("20060102", strconv.Itoa(number))
+	if err != nil {
+		return "", false
+	}
+	return date.Format("2006-01-02"), true
+}
+
+// Count puzzles whose saved date differs from the one repairPuzzleDates gives, and repair them if save is
+// set. Rerun after adding a game's epoch.
+func (s *sqlStore) RepairPuzzleDates(save bool) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	repaired, err := repairPuzzleDates(tx)
+	if err != nil || !save {
+		tx.Rollback()
+		return repaired, err
+	}
+	return repaired, tx.Commit()
+}
+
+// Reset puzzle dates to their canonical date, or the earliest post for games without one. Returns the
+// number of puzzles changed.
+func repairPuzzleDates(tx dbtx) (int, error) {
+	type puzzle struct {
+		game   string
+		number int
+	}
+	dates := map[puzzle]string{}
+	rows, err := tx.Query("SELECT game, game_number, message_id FROM scores")
+	if err != nil {
+		return 0, err
+	}
+	for rows.Next() {
+		var p puzzle
+		var messageID string
+		err := rows.Scan(&p.game, &p.number, &messageID)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		date, canonical, err := puzzleDate(p.game, p.number, messageID)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if previous, ok := dates[p]; !ok || canonical || date < previous {
+			dates[p] = date
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	// Puzzles are read before updating since PostgreSQL can't run a query while reading another
+	var changed []puzzle
+	rows, err = tx.Query("SELECT game, game_number, date FROM puzzles")
+	if err != nil {
+		return 0, err
+	}
+	for rows.Next() {
+		var p puzzle
+		var date string
+		err := rows.Scan(&p.game, &p.number, &date)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if repaired, ok := dates[p]; ok && repaired != date {
+			changed = append(changed, p)
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	for _, p := range changed {
+		_, err = tx.Exec("UPDATE puzzles SET date = ? WHERE game = ? AND game_number = ?", dates[p], p.game, p.number)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(changed), nil
+}
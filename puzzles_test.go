@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestPuzzleDate(t *testing.T) {
+	posted := testMessageID(0)
+	cases := []struct {
+		game      string
+		number    int
+		date      string
+		canonical bool
+	}{
+		{"Wordle", 0, "2021-06-19", true},
+		{"Wordle", 1327, "2025-02-05", true},
+		{"Connections", 1, "2023-06-12", true},
+		{"Strands", 1, "2024-03-04", true},
+		{"Mini Crossword", 20241018, "2024-10-18", true},
+		{"Spelling Bee", 1, "2018-05-09", true},
+		{"Pips Hard", 1, "2025-08-18", true},
+		{"Queens", 1, "2024-04-30", true},
+		{"Crossclimb", 1, "2024-04-30", true},
+		{"Pinpoint", 1, "2024-04-30", true},
+		{"Tango", 1, "2024-10-08", true},
+		{"Zip", 1, "2025-03-18", true},
+		{"Nerdle", 1, "2022-01-20", true},
+		{"Waffle", 1, "2022-01-29", true},
+		{"Deluxe Waffle", 100, "2025-02-05", false},
+		{"Octordle", 1000, "2025-02-05", false},
+	}
+	for _, item := range cases {
+		date, canonical, err := puzzleDate(item.game, item.number, posted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if date != item.date || canonical != item.canonical {
+			t.Errorf("%s %d: expected %s (%v), got %s (%v)", item.game, item.number, item.date, item.canonical, date, canonical)
+		}
+	}
+}
+
+// Message ID posted at a time like 2025-02-05T12:00:00
+func messageIDAt(t *testing.T, posted string) string {
+	snowflake, err := dateToDiscordSnowflake(posted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strconv.FormatInt(snowflake, 10)
+}
+
+func TestLatePostKeepsPuzzleDate(t *testing.T) {
+	testStores(t, func(t *testing.T) {
+		// Backfilled out of order: the late posts are saved first
+		messages := []*discordgo.Message{
+			{ID: messageIDAt(t, "2025-02-09T12:00:00"), Content: "Wordle 1,327 4/6"},
+			{ID: messageIDAt(t, "2025-02-09T12:00:01"), Content: "Framed #1234\n🎥 🟥 🟥 🟩 ⬛ ⬛ ⬛"},
+			{ID: messageIDAt(t, "2025-02-07T12:00:00"), Content: "Framed #1234\n🎥 🟥 🟩 ⬛ ⬛ ⬛ ⬛"},
+			{ID: messageIDAt(t, "2025-02-05T12:00:00"), Content: "Wordle 1,327 3/6"},
+		}
+		for i, msg := range messages {
+			msg.ChannelID = "c1"
+			msg.Author = &discordgo.User{ID: strconv.Itoa(i), Username: "player" + strconv.Itoa(i)}
+			scores, err := ParseScores([]*discordgo.Message{msg})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = store.AddScores(scores, sourceRescan)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		expected := map[string]string{"Wordle": "2025-02-05", "Framed": "2025-02-07"}
+		db := store.(*sqlStore).db
+		for game, date := range expected {
+			var saved string
+			err := db.QueryRow("SELECT date FROM puzzles WHERE game = ?", game).Scan(&saved)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if saved != date {
+				t.Errorf("expected %s on %s, got %s", game, date, saved)
+			}
+		}
+		// Dates saved by older versions are repaired
+		_, err := db.Exec("UPDATE puzzles SET date = '2025-02-09'")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tx.Rollback()
+		repaired, err := repairPuzzleDates(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if repaired != 2 {
+			t.Errorf("expected 2 puzzles repaired, got %d", repaired)
+		}
+	})
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Change found by running a stored message through the current parser. A new score can replace an old
+// one with another ID, such as when a parser fix renames the game.
+type ScoreChange struct {
+	Old *Score // nil for a new score
+	New *Score // nil if the score no longer parses
+}
+
+// Check if the new score takes the place of an old score with another ID
+func (change ScoreChange) Replaces() bool {
+	return change.Old != nil && change.New != nil && change.Old.ID != change.New.ID
+}
+
+func (change ScoreChange) String() string {
+	describe := func(score *Score) string {
+		return fmt.Sprintf("%s %s: %s (%s)", score.Game, score.GameNumber, score.Score, score.Win)
+	}
+	switch {
+	case change.Old == nil:
+		return fmt.Sprintf("+ %s %s %s", change.New.ID, change.New.Username, describe(change.New))
+	case change.New == nil:
+		return fmt.Sprintf("- %s %s %s no longer parses", change.Old.ID, change.Old.Username, describe(change.Old))
+	case change.Replaces():
+		return fmt.Sprintf("~ %s -> %s %s %s -> %s", change.Old.ID, change.New.ID, change.Old.Username, describe(change.Old), describe(change.New))
+	default:
+		return fmt.Sprintf("~ %s %s %s -> %s", change.Old.ID, change.Old.Username, describe(change.Old), describe(change.New))
+	}
+}
+
+// Messages with stored content. Pending review messages that didn't parse are included unless filtering
+// by game.
+func (s *sqlStore) GetStoredMessages(game string, channelID string) ([]*discordgo.Message, error) {
+	db := s.db
+	sql := `
+		SELECT DISTINCT message_id, channel_id, COALESCE(player_id, ''), username, content
+		FROM scores
+		WHERE content IS NOT NULL AND content != ''
+			AND (? = '' OR game = ?) AND (? = '' OR channel_id = ?)
+		UNION
+		SELECT message_id, channel_id, COALESCE(player_id, ''), username, content
+		FROM unparsed
+		WHERE status = 'pending' AND COALESCE(reason, '') != 'repost'
+			AND ? = '' AND (? = '' OR channel_id = ?)
+		ORDER BY message_id
+	`
+	rows, err := db.Query(sql, game, game, channelID, channelID, game, channelID, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stored messages: %v", err)
+	}
+	defer rows.Close()
+	var messages []*discordgo.Message
+	for rows.Next() {
+		msg := discordgo.Message{Author: &discordgo.User{}}
+		err := rows.Scan(&msg.ID, &msg.ChannelID, &msg.Author.ID, &msg.Author.Username, &msg.Content)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, &msg)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// Scores saved for a message, including details
+func (s *sqlStore) GetScoresByMessage(messageID string) ([]Score, error) {
+	return readScores(s.db, "message_id = ?", messageID)
+}
+
+// Saved scores matching a condition, including details
+func readScores(db dbtx, where string, args ...any) ([]Score, error) {
+	sql := `
+		SELECT id, message_id, channel_id, COALESCE(player_id, ''), username, game, game_number, game_number_text, score, score_text, win, hardmode, COALESCE(content, '')
+		FROM scores
+		WHERE ` + where
+	rows, err := db.Query(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var scores []Score
+	for rows.Next() {
+		var score Score
+		err := rows.Scan(
+			&score.ID,
+			&score.MessageID,
+			&score.ChannelID,
+			&score.PlayerID,
+			&score.Username,
+			&score.Game,
+			&score.Number,
+			&score.GameNumber,
+			&score.Value,
+			&score.Score,
+			&score.Win,
+			&score.Hardmode,
+			&score.Content,
+		)
+		if err != nil {
+			return nil, err
+		}
+		scores = append(scores, score)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	// Details are read once the scores are, since PostgreSQL can't run a query while reading another
+	rows.Close()
+	for i := range scores {
+		scores[i].Details, err = scoreDetails(db, scores[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return scores, nil
+}
+
+// Game specific results for a score
+func scoreDetails(db dbtx, scoreID string) (map[string]string, error) {
+	rows, err := db.Query("SELECT name, value FROM score_details WHERE score_id = ?", scoreID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var details map[string]string
+	for rows.Next() {
+		var name, value string
+		err := rows.Scan(&name, &value)
+		if err != nil {
+			return nil, err
+		}
+		if details == nil {
+			details = map[string]string{}
+		}
+		details[name] = value
+	}
+	return details, rows.Err()
+}
+
+// Check if reparsing changed anything worth saving
+func scoreChanged(old Score, new Score) bool {
+	return old.Game != new.Game ||
+		old.GameNumber != new.GameNumber ||
+		old.Number != new.Number ||
+		old.Score != new.Score ||
+		old.Value != new.Value ||
+		old.Win != new.Win ||
+		old.Hardmode != new.Hardmode ||
+		!maps.Equal(old.Details, new.Details)
+}
+
+// Run stored messages through the current parser and compare with what was saved
+func findReparseChanges(game string, channelID string) ([]ScoreChange, error) {
+	messages, err := store.GetStoredMessages(game, channelID)
+	if err != nil {
+		return nil, err
+	}
+	var changes []ScoreChange
+	for _, msg := range messages {
+		saved, err := store.GetScoresByMessage(msg.ID)
+		if err != nil {
+			return nil, err
+		}
+		parsed, _ := ParseScoreFromMessage(msg)
+		// Display names aren't stored with the message, only in the player's name history
+		for i := range parsed {
+			parsed[i].Name = ""
+		}
+		found := map[string]bool{}
+		var added []*Score
+		for i := range parsed {
+			score := &parsed[i]
+			if game != "" && score.Game != game {
+				continue
+			}
+			found[score.ID] = true
+			var old *Score
+			for j := range saved {
+				if saved[j].ID == score.ID {
+					old = &saved[j]
+				}
+			}
+			if old == nil {
+				added = append(added, score)
+			} else if scoreChanged(*old, *score) {
+				changes = append(changes, ScoreChange{Old: old, New: score})
+			}
+		}
+		var gone []*Score
+		for j := range saved {
+			if game != "" && saved[j].Game != game {
+				continue
+			}
+			if !found[saved[j].ID] {
+				gone = append(gone, &saved[j])
+			}
+		}
+		// A new score for the same puzzle replaces one that no longer parses, as does the only new score
+		// when only one went
+		for _, old := range gone {
+			var replacement *Score
+			for i, score := range added {
+				if score.Number == old.Number || (len(added) == 1 && len(gone) == 1) {
+					replacement = score
+					added = append(added[:i], added[i+1:]...)
+					break
+				}
+			}
+			changes = append(changes, ScoreChange{Old: old, New: replacement})
+		}
+		for _, score := range added {
+			changes = append(changes, ScoreChange{New: score})
+		}
+	}
+	return changes, nil
+}
+
+// Reparse stored messages without going to Discord. Changes are listed and confirmed before they are saved.
+// Scores replaced by one with another ID are deleted. Scores that no longer parse are reported but kept.
+// Puzzle dates are repaired along with them.
+func reparse(game string, channelID string, confirm bool, in io.Reader, out io.Writer) error {
+	changes, err := findReparseChanges(game, channelID)
+	if err != nil {
+		return err
+	}
+	var scores []Score
+	var replaced []string
+	for _, change := range changes {
+		fmt.Fprintln(out, change)
+		if change.New != nil {
+			scores = append(scores, *change.New)
+		}
+		if change.Replaces() {
+			replaced = append(replaced, change.Old.ID)
+		}
+	}
+	// Dates saved before a game had an epoch, or by an older version, are set right too
+	dates, err := store.RepairPuzzleDates(false)
+	if err != nil {
+		return err
+	}
+	if dates > 0 {
+		fmt.Fprintf(out, "%d puzzle dates to repair\n", dates)
+	}
+	if len(scores) == 0 && dates == 0 {
+		fmt.Fprintln(out, "No scores to update.")
+		return nil
+	}
+	if confirm {
+		fmt.Fprintf(out, "Save %d scores, delete %d replaced scores and repair %d puzzle dates? [y/N] ", len(scores), len(replaced), dates)
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+			fmt.Fprintln(out, "Nothing saved.")
+			return nil
+		}
+	}
+	err = store.AddScores(scores, sourceReparse)
+	if err != nil {
+		return err
+	}
+	// Deleted once the replacements are saved, so a failure can't lose a score
+	err = store.DeleteScores(replaced, sourceReparse)
+	if err != nil {
+		return err
+	}
+	// Repaired last, as saving scores can add puzzles
+	dates, err = store.RepairPuzzleDates(true)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%d scores saved, %d replaced scores deleted, %d puzzle dates repaired.\n", len(scores), len(replaced), dates)
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestScoreChanged(t *testing.T) {
+	old := Score{Game: "Connections", GameNumber: "100", Score: "1", Win: "Y", Details: map[string]string{"order": "YGBP"}}
+	cases := []struct {
+		new     Score
+		changed bool
+	}{
+		{Score{Game: "Connections", GameNumber: "100", Score: "1", Win: "Y", Details: map[string]string{"order": "YGBP"}, Content: "other"}, false},
+		{Score{Game: "Connections", GameNumber: "100", Score: "2", Win: "Y", Details: map[string]string{"order": "YGBP"}}, true},
+		{Score{Game: "Connections", GameNumber: "100", Score: "1", Win: "Y", Details: map[string]string{"order": "PBGY"}}, true},
+		{Score{Game: "Connections", GameNumber: "100", Score: "1", Win: "Y"}, true},
+	}
+	for i, c := range cases {
+		if scoreChanged(old, c.new) != c.changed {
+			t.Errorf("case %d: expected changed=%v", i, c.changed)
+		}
+	}
+}
+
+func TestReparse(t *testing.T) {
+	useMemoryStore(t)
+	addTestScores(t, map[string][]string{"bob": {"Wordle 1,327 4/6"}})
+	// Saved by an older parser under another game name
+	scores, err := store.GetScoresByMessage(testMessageID(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := scores[0]
+	old.Game = "Wordl"
+	old.ID = scoreID(old.MessageID, old.Game)
+	err = store.DeleteScores([]string{scores[0].ID}, sourceManual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.AddScores([]Score{old}, sourceManual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newID := scoreID(old.MessageID, "Wordle")
+
+	// Declining lists the change and saves nothing
+	var out strings.Builder
+	err = reparse("", "", true, strings.NewReader("n\n"), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "~ "+old.ID+" -> "+newID) || !strings.Contains(out.String(), "Nothing saved.") {
+		t.Errorf("expected the renamed score listed and nothing saved:\n%s", out.String())
+	}
+	checkReparseScores(t, old.ID)
+
+	out.Reset()
+	err = reparse("", "", true, strings.NewReader("y\n"), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "1 scores saved, 1 replaced scores deleted, 0 puzzle dates repaired.") {
+		t.Errorf("expected the renamed score saved:\n%s", out.String())
+	}
+	checkReparseScores(t, newID)
+	log, err := store.GetAuditLog(sourceReparse, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, entry := range log {
+		actions = append(actions, entry.Action+" "+entry.ScoreID)
+	}
+	sort.Strings(actions)
+	if strings.Join(actions, ", ") != "delete "+old.ID+", insert "+newID {
+		t.Errorf("expected the old score deleted and the new one inserted, got %v", actions)
+	}
+
+	// Nothing left to change
+	out.Reset()
+	err = reparse("", "", true, strings.NewReader("y\n"), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "No scores to update.") {
+		t.Errorf("expected nothing to update:\n%s", out.String())
+	}
+}
+
+// Check the only score saved for the first test message
+func checkReparseScores(t *testing.T, id string) {
+	t.Helper()
+	scores, err := store.GetScoresByMessage(testMessageID(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scores) != 1 || scores[0].ID != id {
+		t.Errorf("expected only %s saved, got %+v", id, scores)
+	}
+}
+
+func TestReparseRepairsPuzzleDates(t *testing.T) {
+	useMemoryStore(t)
+	addTestScores(t, map[string][]string{"bob": {"Wordle 1,327 4/6"}})
+	// Saved before Wordle had an epoch, from a late post
+	db := store.(*sqlStore).db
+	_, err := db.Exec("UPDATE puzzles SET date = '2025-02-09'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, answer := range []string{"n", "y"} {
+		var out strings.Builder
+		err = reparse("", "", true, strings.NewReader(answer+"\n"), &out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out.String(), "1 puzzle dates to repair") {
+			t.Errorf("expected the puzzle date listed:\n%s", out.String())
+		}
+	}
+	var date string
+	err = db.QueryRow("SELECT date FROM puzzles WHERE game = 'Wordle'").Scan(&date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if date != "2025-02-05" {
+		t.Errorf("expected the date repaired from the epoch, got %s", date)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Message that looked like a score but failed to parse
+type UnparsedMessage struct {
+	MessageID   string
+	ChannelID   string
+	ChannelName string
+	PlayerID    string
+	Username    string
+	Content     string
+	Error       string
+	Status      string // pending, ignored or resolved
+	Reason      string // Empty if the message didn't parse, "partial" if some of its shares did, or "repost" for a repost that doesn't count
+}
+
+// Player key for the poster, like Score.Player: their user ID, or username for messages saved without one
+func (message UnparsedMessage) Player() string {
+	if message.PlayerID != "" {
+		return message.PlayerID
+	}
+	return message.Username
+}
+
+// Share text hints: result squares, "#123" puzzle numbers or "3/6" style results
+var nearMiss = regexp.MustCompile(`[🟩🟨🟥🟦🟪🟧⬛⬜]|#\d+|\d/\d`)
+
+// Check if a message is worth reviewing. Regular chatter is not kept.
+func isNearMiss(content string) bool {
+	return nearMiss.MatchString(content)
+}
+
+// Keep a message that failed to parse so it can be reviewed later. A partial message had other shares
+// that parsed, so it is kept even if it doesn't look like a near miss, and saving those scores doesn't
+// resolve it.
+func addUnparsedMessage(msg *discordgo.Message, parseErr error, partial bool) error {
+	if msg.Type != 0 || (!partial && !isNearMiss(msg.Content)) {
+		return nil
+	}
+	message := UnparsedMessage{
+		MessageID: msg.ID,
+		ChannelID: msg.ChannelID,
+		Content:   msg.Content,
+		Error:     parseErr.Error(),
+		Status:    "pending",
+	}
+	if partial {
+		message.Reason = "partial"
+	}
+	if msg.Author != nil {
+		message.PlayerID = msg.Author.ID
+		message.Username = msg.Author.Username
+	}
+	return store.AddUnparsedMessage(message)
+}
+
+// Save a message for review. Messages that were already reviewed keep their status.
+func (s *sqlStore) AddUnparsedMessage(message UnparsedMessage) error {
+	_, err := s.db.Exec(`
+		INSERT INTO unparsed (message_id, channel_id, player_id, username, content, error, status, reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (message_id) DO UPDATE SET content = excluded.content, error = excluded.error, reason = excluded.reason
+	`, message.MessageID, message.ChannelID, nullString(message.PlayerID), message.Username, message.Content, message.Error, message.Status, nullString(message.Reason))
+	if err != nil {
+		return fmt.Errorf("failed to add unparsed message %s: %v", message.MessageID, err)
+	}
+	return nil
+}
+
+// List messages waiting for review, newest first
+func (s *sqlStore) GetUnparsedMessages(status string) ([]UnparsedMessage, error) {
+	db := s.db
+	sql := `
+		SELECT u.message_id, u.channel_id, COALESCE(c.name, ''), COALESCE(u.player_id, ''), u.username, u.content, u.error, u.status, COALESCE(u.reason, '')
+		FROM unparsed u
+		LEFT JOIN channels c
+			ON c.channel_id = u.channel_id
+		WHERE u.status = ?
+		ORDER BY u.message_id DESC
+		LIMIT 100
+	`
+	rows, err := db.Query(sql, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unparsed messages: %v", err)
+	}
+	defer rows.Close()
+	var messages []UnparsedMessage
+	for rows.Next() {
+		var message UnparsedMessage
+		err := rows.Scan(
+			&message.MessageID,
+			&message.ChannelID,
+			&message.ChannelName,
+			&message.PlayerID,
+			&message.Username,
+			&message.Content,
+			&message.Error,
+			&message.Status,
+			&message.Reason,
+		)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// Read a single unparsed message
+func (s *sqlStore) GetUnparsedMessage(messageID string) (*UnparsedMessage, error) {
+	db := s.db
+	var message UnparsedMessage
+	row := db.QueryRow("SELECT message_id, channel_id, COALESCE(player_id, ''), username, content, error, status, COALESCE(reason, '') FROM unparsed WHERE message_id = ?", messageID)
+	err := row.Scan(&message.MessageID, &message.ChannelID, &message.PlayerID, &message.Username, &message.Content, &message.Error, &message.Status, &message.Reason)
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// Mark an unparsed message as ignored, resolved or pending again
+func (s *sqlStore) SetUnparsedStatus(messageID string, status string) error {
+	db := s.db
+	_, err := db.Exec("UPDATE unparsed SET status = ? WHERE message_id = ?", status, messageID)
+	return err
+}
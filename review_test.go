@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestNearMiss(t *testing.T) {
+	for _, content := range []string{
+		"Wordle 1,328 3/7",
+		"Contexto #1234 took me 40 guesses",
+		"🟩🟩🟩🟩🟩",
+	} {
+		if !isNearMiss(content) {
+			t.Fatalf("isNearMiss should keep:\n%s", content)
+		}
+	}
+	for _, content := range []string{
+		"Good morning!",
+		"Did anyone get today's Connections?",
+	} {
+		if isNearMiss(content) {
+			t.Fatalf("isNearMiss should skip:\n%s", content)
+		}
+	}
+}
+
+func TestPartialMessageQueued(t *testing.T) {
+	useMemoryStore(t)
+	msg := &discordgo.Message{
+		ID:        testMessageID(0),
+		ChannelID: "c1",
+		Author:    &discordgo.User{ID: "u-bob", Username: "bob"},
+		Content:   "Wordle 1,327 4/6\n\nDaily Quordle 1002\n6️⃣4️⃣",
+	}
+	scores, err := ParseScores([]*discordgo.Message{msg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scores) != 1 || scores[0].Game != "Wordle" {
+		t.Fatalf("expected the Wordle score, got %+v", scores)
+	}
+	// Saving the scores that parsed leaves the message for review
+	err = store.AddScores(scores, sourceRescan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err := store.GetUnparsedMessages("pending")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].Reason != "partial" {
+		t.Errorf("expected the message queued as partial, got %+v", pending)
+	}
+}
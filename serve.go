@@ -1,10 +1,20 @@
 package main
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
 	"embed"
+	"encoding/hex"
 	"html/template"
 	"net/http"
+	"net/url"
+	"os"
 	"strings"
+
+	"github.com/bwmarrin/discordgo"
 )
 
 //go:embed *.tmpl
@@ -20,7 +30,7 @@ func rootHandler(w http.ResponseWriter, r *http.Request) {
 		searchHandler(w, r)
 		return
 	}
-	scores, err := GetRecentScores()
+	scores, err := store.GetRecentScores()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -44,7 +54,7 @@ func rootHandler(w http.ResponseWriter, r *http.Request) {
 // Handler for /?q=
 func searchHandler(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
-	results, err := findByChannelOrUsername(query)
+	results, err := store.FindByChannelOrUsername(query)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -86,7 +96,7 @@ func channelHandler(w http.ResponseWriter, r *http.Request) {
 	if to == "" {
 		to = defaultDateEnd()
 	}
-	games, err := getGameList(channel.GuildID, "", from, to)
+	games, err := store.GetGameList(channel.GuildID, "", from, to)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -95,29 +105,41 @@ func channelHandler(w http.ResponseWriter, r *http.Request) {
 	if game == "" {
 		game = games[0]
 	}
-	stats, err := getStats(game, channel.GuildID, from, to)
+	stats, err := store.GetStats(game, channel.GuildID, from, to)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	var connectionsStats []ConnectionsStats
+	if game == "Connections" {
+		connectionsStats, err = store.GetConnectionsStats(channel.GuildID, from, to)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
 	err = tmpl.ExecuteTemplate(w, "channel.tmpl", struct {
-		ChannelID   string
-		ChannelName string
-		CurrentGame string
-		DateStart   string
-		DateEnd     string
-		Games       []string
-		Stats       []Stats
-		Style       template.CSS
+		ChannelID        string
+		ChannelName      string
+		CurrentGame      string
+		DateStart        string
+		DateEnd          string
+		Games            []string
+		Info             GameInfo
+		Stats            []Stats
+		ConnectionsStats []ConnectionsStats
+		Style            template.CSS
 	}{
-		ChannelID:   channel.ID,
-		ChannelName: channel.Name,
-		CurrentGame: game,
-		DateStart:   from,
-		DateEnd:     to,
-		Games:       games,
-		Stats:       stats,
-		Style:       template.CSS(stylesheet),
+		ChannelID:        channel.ID,
+		ChannelName:      channel.Name,
+		CurrentGame:      game,
+		DateStart:        from,
+		DateEnd:          to,
+		Games:            games,
+		Info:             getGameInfo(game),
+		Stats:            stats,
+		ConnectionsStats: connectionsStats,
+		Style:            template.CSS(stylesheet),
 	})
 	if err != nil {
 		logPrintln("%v", err)
@@ -147,24 +169,25 @@ func statsHandler(w http.ResponseWriter, r *http.Request) {
 	if to == "" {
 		to = defaultDateEnd()
 	}
-	games, err := getGameList(channel.GuildID, "", from, to)
+	games, err := store.GetGameList(channel.GuildID, "", from, to)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	type GameStats struct {
 		Game  string
+		Info  GameInfo
 		Stats []Stats
 	}
 	var gameStats []GameStats
 	for _, game := range games {
-		stats, err := getStats(game, channel.GuildID, from, to)
+		stats, err := store.GetStats(game, channel.GuildID, from, to)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 		if len(stats) > 0 {
-			gameStats = append(gameStats, GameStats{Game: game, Stats: stats})
+			gameStats = append(gameStats, GameStats{Game: game, Info: getGameInfo(game), Stats: stats})
 		}
 	}
 	err = tmpl.ExecuteTemplate(w, "stats.tmpl", struct {
@@ -192,11 +215,20 @@ func statsHandler(w http.ResponseWriter, r *http.Request) {
 // Handler for /user
 func userHandler(w http.ResponseWriter, r *http.Request) {
 	params := r.URL.Query()
-	username := params.Get("name")
-	if username == "" {
+	name := params.Get("name")
+	if name == "" {
 		http.Error(w, "Username Required", http.StatusInternalServerError)
 		return
 	}
+	player, err := store.GetPlayer(name)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Player Not Found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	from := params.Get("from")
 	if from == "" {
 		from = defaultDateStart()
@@ -205,7 +237,7 @@ func userHandler(w http.ResponseWriter, r *http.Request) {
 	if to == "" {
 		to = defaultDateEnd()
 	}
-	games, err := getGameList("", username, from, to)
+	games, err := store.GetGameList("", player.ID, from, to)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -214,35 +246,35 @@ func userHandler(w http.ResponseWriter, r *http.Request) {
 	if game == "" {
 		game = games[0]
 	}
-	friends, err := getFriendNames(username)
+	friends, err := store.GetFriends(player.ID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	scores, err := getScoresByUser(game, username, from, to)
+	scores, err := store.GetScoresByUser(game, player.ID, from, to)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	var barMax int
-	switch {
-	case strings.Contains(game, "Octordle"):
-		barMax = 100
-	default:
-		barMax = 7
+	barMax := 7
+	for _, parser := range gameParsers {
+		// Multi-board games add up every board
+		if multiboard, ok := parser.(multiboardParser); ok && strings.Contains(game, multiboard.name) {
+			barMax = multiboard.boards * multiboard.penalty
+		}
 	}
 	err = tmpl.ExecuteTemplate(w, "user.tmpl", struct {
-		Username    string
+		Player      *Player
 		BarMax      int
 		CurrentGame string
 		DateStart   string
 		DateEnd     string
-		Friends     []string
+		Friends     []Player
 		Games       []string
 		Scores      []Score
 		Style       template.CSS
 	}{
-		Username:    username,
+		Player:      player,
 		BarMax:      barMax,
 		CurrentGame: game,
 		DateStart:   from,
@@ -259,6 +291,34 @@ func userHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Handler for /score. Shows every recorded change to a score.
+func scoreHandler(w http.ResponseWriter, r *http.Request) {
+	scoreID := r.URL.Query().Get("id")
+	if scoreID == "" {
+		http.Error(w, "Score Required", http.StatusBadRequest)
+		return
+	}
+	history, err := store.GetScoreHistory(scoreID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = tmpl.ExecuteTemplate(w, "score.tmpl", struct {
+		ScoreID string
+		History []ScoreAudit
+		Style   template.CSS
+	}{
+		ScoreID: scoreID,
+		History: history,
+		Style:   template.CSS(stylesheet),
+	})
+	if err != nil {
+		logPrintln("%v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 // Handler for /attendance
 func attendanceHandler(w http.ResponseWriter, r *http.Request) {
 	params := r.URL.Query()
@@ -276,7 +336,7 @@ func attendanceHandler(w http.ResponseWriter, r *http.Request) {
 	if month == "" {
 		month = getCurrentMonth()
 	}
-	stats, err := getAttendanceStatsForMonth(channel.GuildID, month)
+	stats, err := store.GetAttendanceStatsForMonth(channel.GuildID, month)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -307,9 +367,214 @@ func attendanceHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Admin pages change scores, so they need the admin password (MINDARI_ADMIN_PASSWORD)
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	password := os.Getenv("MINDARI_ADMIN_PASSWORD")
+	_, given, ok := r.BasicAuth()
+	if password == "" || !ok || subtle.ConstantTimeCompare([]byte(given), []byte(password)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Basic realm="mindari"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// Key for CSRF tokens, new each time the server starts
+var csrfKey = func() []byte {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}()
+
+// Token admin forms post back, tied to the admin login so another site can't submit them
+func csrfToken(r *http.Request) string {
+	username, password, _ := r.BasicAuth()
+	mac := hmac.New(sha256.New, csrfKey)
+	mac.Write([]byte(username + ":" + password))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Check an admin form was posted from our own pages. The browser's Origin, when sent, must be this host and
+// the form must carry the token.
+func checkCSRF(w http.ResponseWriter, r *http.Request) bool {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		originURL, err := url.Parse(origin)
+		if err != nil || originURL.Host != r.Host {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return false
+		}
+	}
+	if !hmac.Equal([]byte(r.FormValue("csrf")), []byte(csrfToken(r))) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// Handler for /review
+func reviewHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	if r.Method == http.MethodPost {
+		if checkCSRF(w, r) {
+			reviewActionHandler(w, r)
+		}
+		return
+	}
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = "pending"
+	}
+	messages, err := store.GetUnparsedMessages(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = tmpl.ExecuteTemplate(w, "review.tmpl", struct {
+		Status   string
+		Messages []UnparsedMessage
+		CSRF     string
+		Style    template.CSS
+	}{
+		Status:   status,
+		Messages: messages,
+		CSRF:     csrfToken(r),
+		Style:    template.CSS(stylesheet),
+	})
+	if err != nil {
+		logPrintln("%v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// Handler for POST /review. Ignores, restores or hand-enters a score for a message.
+func reviewActionHandler(w http.ResponseWriter, r *http.Request) {
+	messageID := r.FormValue("id")
+	message, err := store.GetUnparsedMessage(messageID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch r.FormValue("action") {
+	case "ignore":
+		err = store.SetUnparsedStatus(message.MessageID, "ignored")
+	case "restore":
+		err = store.SetUnparsedStatus(message.MessageID, "pending")
+	case "count":
+		// Count a repost, replacing the score that counted before
+		var scores []Score
+		scores, err = ParseScoreFromMessage(&discordgo.Message{
+			ID:        message.MessageID,
+			ChannelID: message.ChannelID,
+			Author:    &discordgo.User{ID: message.PlayerID, Username: message.Username},
+			Content:   message.Content,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for i := range scores {
+			scores[i].Name = ""
+		}
+		err = store.AddScores(scores, sourceManual)
+		if err == nil {
+			err = store.SetUnparsedStatus(message.MessageID, "resolved")
+		}
+	case "score":
+		game := r.FormValue("game")
+		if game == "" || r.FormValue("game_number") == "" || r.FormValue("score") == "" {
+			http.Error(w, "Game, Number and Score Required", http.StatusBadRequest)
+			return
+		}
+		score := Score{
+			ID:         scoreID(message.MessageID, game),
+			MessageID:  message.MessageID,
+			ChannelID:  message.ChannelID,
+			PlayerID:   message.PlayerID,
+			Username:   message.Username,
+			Game:       game,
+			GameNumber: r.FormValue("game_number"),
+			Score:      r.FormValue("score"),
+			Win:        yesNo(r.FormValue("win") == "Y"),
+		}
+		err = normalizeNumbers(&score)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		err = store.AddScores([]Score{score}, sourceManual)
+		if err == nil {
+			err = store.SetUnparsedStatus(message.MessageID, "resolved")
+		}
+	default:
+		http.Error(w, "Unknown Action", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/review?status="+url.QueryEscape(r.FormValue("status")), http.StatusSeeOther)
+}
+
+// Handler for /players. Lists merged identities and merges or unmerges them.
+func playersHandler(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	if r.Method == http.MethodPost {
+		if !checkCSRF(w, r) {
+			return
+		}
+		var err error
+		switch r.FormValue("action") {
+		case "merge":
+			err = store.MergePlayers(r.FormValue("from"), r.FormValue("into"))
+		case "unmerge":
+			err = store.UnmergePlayer(r.FormValue("from"))
+		default:
+			http.Error(w, "Unknown Action", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Redirect(w, r, "/players", http.StatusSeeOther)
+		return
+	}
+	aliases, err := store.GetPlayerAliases()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = tmpl.ExecuteTemplate(w, "players.tmpl", struct {
+		Aliases []PlayerAlias
+		CSRF    string
+		Style   template.CSS
+	}{
+		Aliases: aliases,
+		CSRF:    csrfToken(r),
+		Style:   template.CSS(stylesheet),
+	})
+	if err != nil {
+		logPrintln("%v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 func startWebServer(addr string) error {
 	http.HandleFunc("/attendance", attendanceHandler)
 	http.HandleFunc("/channel", channelHandler)
+	http.HandleFunc("/players", playersHandler)
+	http.HandleFunc("/review", reviewHandler)
+	http.HandleFunc("/score", scoreHandler)
 	http.HandleFunc("/stats", statsHandler)
 	http.HandleFunc("/user", userHandler)
 	http.HandleFunc("/", rootHandler)
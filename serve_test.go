@@ -0,0 +1,131 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestStatsHandler(t *testing.T) {
+	useMemoryStore(t)
+	addTestScores(t, map[string][]string{
+		"bob": {"Wordle 1,327 4/6"},
+		"amy": {"Wordle 1,327 3/6"},
+	})
+	w := httptest.NewRecorder()
+	statsHandler(w, httptest.NewRequest("GET", "/stats?cid=c1&from="+testFrom+"&to="+testTo, nil))
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "Wordle") || strings.Index(body, "amy") > strings.Index(body, "bob") {
+		t.Errorf("expected Wordle stats with amy first:\n%s", body)
+	}
+}
+
+func TestUserHandler(t *testing.T) {
+	useMemoryStore(t)
+	addTestScores(t, map[string][]string{"bob": {"Wordle 1,327 4/6"}})
+	w := httptest.NewRecorder()
+	userHandler(w, httptest.NewRequest("GET", "/user?name=bob&from="+testFrom+"&to="+testTo, nil))
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	if !strings.Contains(w.Body.String(), "1,327") {
+		t.Errorf("expected puzzle 1,327:\n%s", w.Body)
+	}
+}
+
+// Admin form post logged in with the admin password
+func adminRequest(t *testing.T, target string, form url.Values) *http.Request {
+	t.Helper()
+	t.Setenv("MINDARI_ADMIN_PASSWORD", "secret")
+	r := httptest.NewRequest("POST", target, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.SetBasicAuth("admin", "secret")
+	return r
+}
+
+func TestReviewCSRF(t *testing.T) {
+	useMemoryStore(t)
+	addTestScores(t, map[string][]string{"bob": {"Wordle 1,327 ?/6 🟩🟩"}})
+	form := url.Values{"id": {testMessageID(0)}, "action": {"ignore"}}
+	token := csrfToken(adminRequest(t, "/review", form))
+
+	forged := []*http.Request{adminRequest(t, "/review", form)}
+	form.Set("csrf", token)
+	r := adminRequest(t, "/review", form)
+	r.Header.Set("Origin", "https://attacker.test")
+	forged = append(forged, r)
+	for _, r := range forged {
+		w := httptest.NewRecorder()
+		reviewHandler(w, r)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("expected a forged post refused, got %d", w.Code)
+		}
+	}
+	message, err := store.GetUnparsedMessage(testMessageID(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Status != "pending" {
+		t.Fatalf("expected the message left alone, got %s", message.Status)
+	}
+
+	r = adminRequest(t, "/review", form)
+	r.Header.Set("Origin", "http://"+r.Host)
+	w := httptest.NewRecorder()
+	reviewHandler(w, r)
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("expected a redirect, got %d: %s", w.Code, w.Body)
+	}
+	message, err = store.GetUnparsedMessage(testMessageID(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Status != "ignored" {
+		t.Errorf("expected the message ignored, got %s", message.Status)
+	}
+}
+
+func TestPlayersCSRF(t *testing.T) {
+	useMemoryStore(t)
+	addTestScores(t, map[string][]string{"bob": {"Wordle 1,327 4/6"}, "bobalt": {"Wordle 1,328 3/6"}})
+	form := url.Values{"from": {"u-bobalt"}, "into": {"u-bob"}, "action": {"merge"}}
+	w := httptest.NewRecorder()
+	playersHandler(w, adminRequest(t, "/players", form))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected a post without a token refused, got %d", w.Code)
+	}
+	r := adminRequest(t, "/players", form)
+	form.Set("csrf", csrfToken(r))
+	w = httptest.NewRecorder()
+	playersHandler(w, adminRequest(t, "/players", form))
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("expected a redirect, got %d: %s", w.Code, w.Body)
+	}
+	aliases, err := store.GetPlayerAliases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(aliases) != 1 || aliases[0].AliasID != "u-bobalt" {
+		t.Errorf("expected only the merge with a token saved, got %+v", aliases)
+	}
+}
+
+func TestReviewPageLinksPlayer(t *testing.T) {
+	useMemoryStore(t)
+	addTestScores(t, map[string][]string{"bob": {"Wordle 1,327 ?/6 🟩🟩"}})
+	r := adminRequest(t, "/review", nil)
+	r.Method = "GET"
+	w := httptest.NewRecorder()
+	reviewHandler(w, r)
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	if !strings.Contains(w.Body.String(), `href="/user?name=u-bob"`) {
+		t.Errorf("expected a link to bob by user ID:\n%s", w.Body)
+	}
+}
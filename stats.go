@@ -9,7 +9,8 @@ import (
 )
 
 type Stats struct {
-	Username string
+	PlayerID string
+	Username string // Display name
 	Count    int
 	Lowest   float32
 	Average  float32
@@ -17,16 +18,13 @@ type Stats struct {
 }
 
 // Get a list of channels.
-func getChannelList() ([]string, error) {
-	db, err := getDatabase()
-	if err != nil {
-		return nil, err
-	}
+func (s *sqlStore) GetChannelList() ([]string, error) {
+	db := s.db
 	var rows *sql.Rows
 	sql := `
 		SELECT channel_id
 		FROM channels c`
-	rows, err = db.Query(sql)
+	rows, err := db.Query(sql)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get games: %v", err)
 	}
@@ -43,11 +41,9 @@ func getChannelList() ([]string, error) {
 }
 
 // Get a list of games. Add guild or user to filter the list.
-func getGameList(guildID string, username string, from string, to string) ([]string, error) {
-	db, err := getDatabase()
-	if err != nil {
-		return nil, err
-	}
+func (s *sqlStore) GetGameList(guildID string, player string, from string, to string) ([]string, error) {
+	db := s.db
+	var err error
 	if from == "" {
 		from = defaultDateStart()
 	}
@@ -55,17 +51,17 @@ func getGameList(guildID string, username string, from string, to string) ([]str
 		to = defaultDateEnd()
 	}
 	var rows *sql.Rows
-	if guildID != "" && username != "" {
+	if guildID != "" && player != "" {
 		sql := `
 			SELECT DISTINCT p.game 
 			FROM scores s
 			JOIN puzzles p
 				ON s.game = p.game AND s.game_number = p.game_number
 			JOIN channels c
-				ON c.channel_id = s.channel_id
-			WHERE c.guild_id = ? AND username = ?
+				ON c.channel_id = s.channel_id` + playerJoins + `
+			WHERE c.guild_id = ? AND ` + playerKey + ` = ?
 				AND p.date >= ? AND p.date <= ?`
-		rows, err = db.Query(sql, guildID, username, from, to)
+		rows, err = db.Query(sql, guildID, player, from, to)
 	} else if guildID != "" {
 		sql := `
 			SELECT DISTINCT p.game 
@@ -76,14 +72,14 @@ func getGameList(guildID string, username string, from string, to string) ([]str
 				ON s.game = p.game AND s.game_number = p.game_number
 			WHERE c.guild_id = ? AND p.date >= ? AND p.date <= ?`
 		rows, err = db.Query(sql, guildID, from, to)
-	} else if username != "" {
+	} else if player != "" {
 		sql := `
 			SELECT DISTINCT p.game 
 			FROM scores s 
 			JOIN puzzles p
-				ON s.game = p.game AND s.game_number = p.game_number
-			WHERE username = ? AND p.date >= ? AND p.date <= ?`
-		rows, err = db.Query(sql, username, from, to)
+				ON s.game = p.game AND s.game_number = p.game_number` + playerJoins + `
+			WHERE ` + playerKey + ` = ? AND p.date >= ? AND p.date <= ?`
+		rows, err = db.Query(sql, player, from, to)
 	} else {
 		sql := `
 			SELECT DISTINCT p.game 
@@ -112,11 +108,8 @@ func getGameList(guildID string, username string, from string, to string) ([]str
 }
 
 // Aggregate stats for a game.
-func getStats(game string, guildID string, from string, to string) ([]Stats, error) {
-	db, err := getDatabase()
-	if err != nil {
-		return nil, err
-	}
+func (s *sqlStore) GetStats(game string, guildID string, from string, to string) ([]Stats, error) {
+	db := s.db
 	if from == "" {
 		from = defaultDateStart()
 	}
@@ -125,17 +118,20 @@ func getStats(game string, guildID string, from string, to string) ([]Stats, err
 	}
 	var rows *sql.Rows
 	sql := `
-		SELECT username, COUNT(id), MIN(score), AVG(score), MAX(score)
+		SELECT ` + playerKey + `, MAX(COALESCE(pl.display_name, s.username)), COUNT(id), MIN(score), AVG(score), MAX(score)
 		FROM scores s
 		JOIN channels c
 			ON c.channel_id = s.channel_id
 		JOIN puzzles p
-			ON s.game = p.game AND s.game_number = p.game_number
+			ON s.game = p.game AND s.game_number = p.game_number` + playerJoins + `
 		WHERE s.game = ? AND guild_id = ? AND p.date >= ? AND p.date <= ?
-		GROUP BY username
-		ORDER BY 4
+		GROUP BY ` + playerKey + `
+		ORDER BY 5
 	`
-	rows, err = db.Query(sql, game, guildID, from, to)
+	if getGameInfo(game).HigherIsBetter {
+		sql += " DESC"
+	}
+	rows, err := db.Query(sql, game, guildID, from, to)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get stats: %v", err)
 	}
@@ -143,6 +139,7 @@ func getStats(game string, guildID string, from string, to string) ([]Stats, err
 	for rows.Next() {
 		var stat Stats
 		err := rows.Scan(
+			&stat.PlayerID,
 			&stat.Username,
 			&stat.Count,
 			&stat.Lowest,
@@ -160,6 +157,77 @@ func getStats(game string, guildID string, from string, to string) ([]Stats, err
 	return stats, nil
 }
 
+type ConnectionsStats struct {
+	PlayerID       string
+	Username       string // Display name
+	Count          int
+	Mistakes       float32
+	Perfect        int
+	PurpleFirst    int
+	ReverseRainbow int
+}
+
+// Connections leaderboard ranked by fewest mistakes, with achievement counts
+func (s *sqlStore) GetConnectionsStats(guildID string, from string, to string) ([]ConnectionsStats, error) {
+	db := s.db
+	if from == "" {
+		from = defaultDateStart()
+	}
+	if to == "" {
+		to = defaultDateEnd()
+	}
+	sql := `
+		SELECT
+			` + playerKey + `,
+			MAX(COALESCE(pl.display_name, s.username)),
+			COUNT(id),
+			AVG(CAST(m.value AS INTEGER)),
+			SUM(CASE WHEN m.value = '0' THEN 1 ELSE 0 END),
+			SUM(CASE WHEN pf.value = 'Y' THEN 1 ELSE 0 END),
+			SUM(CASE WHEN rr.value = 'Y' THEN 1 ELSE 0 END)
+		FROM scores s
+		JOIN channels c
+			ON c.channel_id = s.channel_id
+		JOIN puzzles p
+			ON s.game = p.game AND s.game_number = p.game_number
+		JOIN score_details m
+			ON m.score_id = s.id AND m.name = 'mistakes'
+		LEFT JOIN score_details pf
+			ON pf.score_id = s.id AND pf.name = 'purple_first'
+		LEFT JOIN score_details rr
+			ON rr.score_id = s.id AND rr.name = 'reverse_rainbow'` + playerJoins + `
+		WHERE s.game = 'Connections' AND guild_id = ? AND p.date >= ? AND p.date <= ?
+		GROUP BY ` + playerKey + `
+		ORDER BY 4, 3 DESC
+	`
+	rows, err := db.Query(sql, guildID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connections stats: %v", err)
+	}
+	defer rows.Close()
+	var stats []ConnectionsStats
+	for rows.Next() {
+		var stat ConnectionsStats
+		err := rows.Scan(
+			&stat.PlayerID,
+			&stat.Username,
+			&stat.Count,
+			&stat.Mistakes,
+			&stat.Perfect,
+			&stat.PurpleFirst,
+			&stat.ReverseRainbow,
+		)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, stat)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
 func PrintStats(stats []Stats, format string) {
 	fmt.Print(SPrintStats(stats, format))
 }
@@ -187,6 +255,29 @@ func SPrintStatsMarkdownDiscord(stats []Stats) string {
 	return builder.String()
 }
 
+func SPrintConnectionsStatsMarkdownDiscord(stats []ConnectionsStats) string {
+	usernameColumnTitle := "Username"
+	usernameColumnSize := len(usernameColumnTitle)
+	for _, stat := range stats {
+		if len(stat.Username) > usernameColumnSize {
+			usernameColumnSize = len(stat.Username)
+		}
+	}
+	usernameColumnTitle = fmt.Sprintf("%-*s", usernameColumnSize, usernameColumnTitle)
+	var builder strings.Builder
+	builder.WriteString("```md\n")
+	header := fmt.Sprintf("| %s |  # | Mistakes | Perfect | Purple 1st | Rainbow |\n", usernameColumnTitle)
+	builder.WriteString(header)
+	linebreak := fmt.Sprintf("| %s | -- | -------- | ------- | ---------- | ------- |\n", strings.Repeat("-", usernameColumnSize))
+	builder.WriteString(linebreak)
+	for _, stat := range stats {
+		s := fmt.Sprintf("| %-*s | %2d | %8.2f | %7d | %10d | %7d |\n", usernameColumnSize, stat.Username, stat.Count, stat.Mistakes, stat.Perfect, stat.PurpleFirst, stat.ReverseRainbow)
+		builder.WriteString(s)
+	}
+	builder.WriteString("```\n")
+	return builder.String()
+}
+
 func SPrintStatsTabs(stats []Stats) string {
 	var builder strings.Builder
 	builder.WriteString("Username\tGames\tLowest\tAverage\tHighest\n")
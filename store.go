@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Persistence for scores, channels and the review queue
+type Store interface {
+	// Scores
+	AddScores(scores []Score, source string) error
+	DeleteScores(scoreIDs []string, source string) error
+	GetRecentScores() ([]Score, error)
+	GetScoresByUser(game string, player string, from string, to string) ([]Score, error)
+	GetScoresByMessage(messageID string) ([]Score, error)
+	GetStoredMessages(game string, channelID string) ([]*discordgo.Message, error)
+	GetScoreIDRange() (string, string, error)
+	GetMostRecentMessageID(channelID string) (string, error)
+	RepairPuzzleDates(save bool) (int, error)
+
+	// Channels and players
+	AddChannel(channel *discordgo.Channel) error
+	GetChannel(channelID string) (*discordgo.Channel, error)
+	GetChannelList() ([]string, error)
+	GetPlayer(key string) (*Player, error)
+	GetFriends(player string) ([]Player, error)
+	BackfillPlayerIDs(messages []*discordgo.Message, source string) error
+	GetChannelsMissingPlayerIDs() ([]string, error)
+	MergePlayers(from string, into string) error
+	UnmergePlayer(key string) error
+	GetPlayerAliases() ([]PlayerAlias, error)
+	FindByChannelOrUsername(query string) ([]SearchResult, error)
+
+	// Stats
+	GetGameList(guildID string, player string, from string, to string) ([]string, error)
+	GetStats(game string, guildID string, from string, to string) ([]Stats, error)
+	GetConnectionsStats(guildID string, from string, to string) ([]ConnectionsStats, error)
+	GetAttendanceStatsForMonth(guildID string, month string) ([]AttendanceStats, error)
+
+	// Audit log
+	GetScoreHistory(scoreID string) ([]ScoreAudit, error)
+	GetAuditLog(source string, limit int) ([]ScoreAudit, error)
+
+	// Guild settings
+	GetDuplicatePolicy(guildID string) (string, error)
+	SetDuplicatePolicy(guildID string, policy string) error
+
+	// Review queue
+	AddUnparsedMessage(message UnparsedMessage) error
+	GetUnparsedMessages(status string) ([]UnparsedMessage, error)
+	GetUnparsedMessage(messageID string) (*UnparsedMessage, error)
+	SetUnparsedStatus(messageID string, status string) error
+
+	// Export and import
+	ExportChannels() ([]ChannelRecord, error)
+	ExportPuzzles() ([]PuzzleRecord, error)
+	ExportPlayers() ([]PlayerRecord, error)
+	ExportScores() ([]ScoreRecord, error)
+	ImportChannels(records []ChannelRecord) error
+	ImportPuzzles(records []PuzzleRecord) error
+	ImportPlayers(records []PlayerRecord) error
+
+	// Write a copy of the database to a new file while it's in use
+	Backup(path string) error
+	Close() error
+}
+
+// Shared store, opened by main before running a command
+var store Store
+
+// Database to use when -db isn't given. MINDARI_DB can point somewhere other than the working directory.
+func defaultDatabase() string {
+	dsn := os.Getenv("MINDARI_DB")
+	if dsn == "" {
+		dsn = "./scores.db"
+	}
+	return dsn
+}
+
+// Open the store for a SQLite path or PostgreSQL DSN
+func openStore(dsn string) (Store, error) {
+	return newSQLStore(dsn)
+}